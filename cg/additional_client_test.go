@@ -0,0 +1,36 @@
+package cg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestConnectAdditionalClient(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice", cg.WithEventBufferSize(3), cg.WithStrictDecoding(true))
+
+	additional, err := socket.ConnectAdditionalClient()
+	if err != nil {
+		t.Fatalf("failed to connect additional client: %s", err)
+	}
+	defer additional.Close()
+	if additional.PlayerID() != socket.PlayerID() {
+		t.Errorf("expected player %s, got %s", socket.PlayerID(), additional.PlayerID())
+	}
+	if capacity := cap(additional.EventChan()); capacity != 3 {
+		t.Errorf("expected the event buffer size to be inherited, got %d", capacity)
+	}
+
+	server.EmitTo(socket.PlayerID(), "move", map[string]int{"x": 1, "unknown": 2})
+	event := cgtest.ExpectEvent(t, additional, "move", time.Second)
+	var move struct {
+		X int `json:"x"`
+	}
+	if err := event.UnmarshalData(&move); err == nil {
+		t.Error("expected strict decoding to be inherited")
+	}
+	cgtest.ExpectEvent(t, socket, "move", time.Second)
+}
@@ -1,25 +1,30 @@
 package cg
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-
-	"github.com/gorilla/websocket"
+	neturl "net/url"
+	"os"
+	"path/filepath"
 )
 
+// connect establishes the first connection of the socket as the player. See redial for later connections.
 func (s *Socket) connect(gameID, playerID, playerSecret string) error {
-	wsConn, _, err := websocket.DefaultDialer.Dial(baseURL("ws", s.tls, "%s/api/games/%s/players/%s/connect?player_secret=%s", s.gameURL, gameID, playerID, playerSecret), nil)
+	wsConn, err := s.dialPlayer(gameID, playerID, playerSecret)
 	if err != nil {
 		return err
 	}
 	s.wsConn = wsConn
+	s.playerSecret = playerSecret
 	return nil
 }
 
+// spectate establishes the first connection of the socket as a spectator. See redial for later connections.
 func (s *Socket) spectate(gameID string) error {
-	wsConn, _, err := websocket.DefaultDialer.Dial(baseURL("ws", s.tls, "%s/api/games/%s/spectate", s.gameURL, gameID), nil)
+	wsConn, err := s.dialSpectator(gameID)
 	if err != nil {
 		return err
 	}
@@ -27,19 +32,56 @@ func (s *Socket) spectate(gameID string) error {
 	return nil
 }
 
+func (s *Socket) dialPlayer(gameID, playerID, playerSecret string) (wsConnection, error) {
+	return dialWebsocket(baseURL("ws", s.tls, "%s/api/games/%s/players/%s/connect?player_secret=%s", s.gameURL, gameID, playerID, playerSecret), s.dialConfig)
+}
+
+func (s *Socket) dialSpectator(gameID string) (wsConnection, error) {
+	if len(s.multiplexGames) > 0 {
+		query := neturl.Values{"game_id": s.multiplexGames}.Encode()
+		return dialWebsocket(baseURL("ws", s.tls, "%s/api/spectate?%s", s.gameURL, query), s.dialConfig)
+	}
+	return dialWebsocket(baseURL("ws", s.tls, "%s/api/games/%s/spectate", s.gameURL, gameID), s.dialConfig)
+}
+
+func (s *Socket) deletePlayer() error {
+	req, err := http.NewRequest(http.MethodDelete, baseURL("http", s.tls, "%s/api/games/%s/players/%s?player_secret=%s", s.gameURL, s.gameID, s.playerID, s.playerSecret), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.dialConfig.httpClient().Do(req)
+	if err != nil {
+		// The URL of the error contains the player secret.
+		return redactError(err)
+	}
+	defer resp.Body.Close()
+	err = checkStatus(resp, "leave game", http.StatusOK, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// fetchUsername returns the username of playerID using the REST cache shared by all sockets on the server.
 func (s *Socket) fetchUsername(gameID, playerID string) (string, error) {
-	resp, err := http.Get(baseURL("http", s.tls, "%s/api/games/%s/players/%s", s.gameURL, gameID, playerID))
+	username, err := sharedRESTCache(s.gameURL).get("games/"+gameID+"/players/"+playerID, func() (any, error) {
+		return s.requestUsername(gameID, playerID)
+	})
+	if err != nil {
+		return "", err
+	}
+	return username.(string), nil
+}
+
+func (s *Socket) requestUsername(gameID, playerID string) (string, error) {
+	resp, err := s.dialConfig.httpClient().Get(baseURL("http", s.tls, "%s/api/games/%s/players/%s", s.gameURL, gameID, playerID))
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		var data []byte
-		data, err = io.ReadAll(resp.Body)
-		if err == nil && len(data) > 0 {
-			return "", fmt.Errorf("failed to fetch username of %s: %s", playerID, string(data))
-		}
-		return "", fmt.Errorf("invalid response; expected: %d, got: %d", http.StatusOK, resp.StatusCode)
+	err = checkStatus(resp, "fetch username of "+playerID, http.StatusOK)
+	if err != nil {
+		return "", err
 	}
 
 	type response struct {
@@ -50,19 +92,27 @@ func (s *Socket) fetchUsername(gameID, playerID string) (string, error) {
 	return r.Username, err
 }
 
+// fetchPlayers returns the players of the game using the REST cache shared by all sockets on the server.
+// The returned map must not be modified.
 func (s *Socket) fetchPlayers(gameID string) (map[string]string, error) {
-	resp, err := http.Get(baseURL("http", s.tls, "%s/api/games/%s/players", s.gameURL, gameID))
+	players, err := sharedRESTCache(s.gameURL).get("games/"+gameID+"/players", func() (any, error) {
+		return s.requestPlayers(gameID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return players.(map[string]string), nil
+}
+
+func (s *Socket) requestPlayers(gameID string) (map[string]string, error) {
+	resp, err := s.dialConfig.httpClient().Get(baseURL("http", s.tls, "%s/api/games/%s/players", s.gameURL, gameID))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		var data []byte
-		data, err = io.ReadAll(resp.Body)
-		if err == nil && len(data) > 0 {
-			return nil, fmt.Errorf("failed to fetch players: %s", string(data))
-		}
-		return nil, fmt.Errorf("invalid response; expected: %d, got: %d", http.StatusOK, resp.StatusCode)
+	err = checkStatus(resp, "fetch players", http.StatusOK)
+	if err != nil {
+		return nil, err
 	}
 
 	var r map[string]string
@@ -70,28 +120,221 @@ func (s *Socket) fetchPlayers(gameID string) (map[string]string, error) {
 	return r, err
 }
 
+// postJSON sends body encoded as JSON to url and decodes the response into result.
+func postJSON(client *http.Client, url string, expectedStatus int, body, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return ErrEncodeFailed
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	err = checkStatus(resp, "send request", expectedStatus)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// CreateGame creates a new game on the server at gameURL.
+// The join secret is only returned if protected is true.
+// opts configure the connection to the server, e.g. with WithPinnedPublicKeys.
+func CreateGame(gameURL string, public, protected bool, config any, opts ...DialOption) (gameID, joinSecret string, err error) {
+	gameURL = trimURL(gameURL)
+	dialConfig := newDialConfig(gameURL, opts)
+
+	type request struct {
+		Public    bool `json:"public"`
+		Protected bool `json:"protected"`
+		Config    any  `json:"config,omitempty"`
+	}
+	type response struct {
+		GameID     string `json:"game_id"`
+		JoinSecret string `json:"join_secret"`
+	}
+	var r response
+	err = postJSON(dialConfig.httpClient(), baseURL("http", probeTLS(gameURL, dialConfig), "%s/api/games", gameURL), http.StatusCreated, request{
+		Public:    public,
+		Protected: protected,
+		Config:    config,
+	}, &r)
+	return r.GameID, r.JoinSecret, err
+}
+
+// JoinGame creates a new player in the game.
+// joinSecret is only required if the game is protected. See RememberUsername to make username the default of DefaultUsername.
+// opts configure the connection to the server, e.g. with WithPinnedPublicKeys.
+func JoinGame(gameURL, gameID, username, joinSecret string, opts ...DialOption) (playerID, playerSecret string, err error) {
+	gameURL = trimURL(gameURL)
+	dialConfig := newDialConfig(gameURL, opts)
+
+	type request struct {
+		Username   string `json:"username"`
+		JoinSecret string `json:"join_secret,omitempty"`
+	}
+	type response struct {
+		PlayerID     string `json:"player_id"`
+		PlayerSecret string `json:"player_secret"`
+	}
+	var r response
+	err = postJSON(dialConfig.httpClient(), baseURL("http", probeTLS(gameURL, dialConfig), "%s/api/games/%s/players", gameURL, gameID), http.StatusCreated, request{
+		Username:   username,
+		JoinSecret: joinSecret,
+	}, &r)
+	return r.PlayerID, r.PlayerSecret, err
+}
+
+// GameInfo describes a public game running on a server.
+type GameInfo struct {
+	ID      string `json:"id"`
+	Players int    `json:"players"`
+	// Alias is an optional human-friendly name assigned by the server.
+	Alias string `json:"alias,omitempty"`
+}
+
+// ListGames returns all public games on the server at gameURL and the number of private games.
+// opts configure the connection to the server, e.g. with WithPinnedPublicKeys.
+func ListGames(gameURL string, opts ...DialOption) (public []GameInfo, private int, err error) {
+	gameURL = trimURL(gameURL)
+	dialConfig := newDialConfig(gameURL, opts)
+	resp, err := dialConfig.httpClient().Get(baseURL("http", probeTLS(gameURL, dialConfig), "%s/api/games", gameURL))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	err = checkStatus(resp, "list games", http.StatusOK)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	type response struct {
+		Private int        `json:"private"`
+		Public  []GameInfo `json:"public"`
+	}
+	var r response
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	return r.Public, r.Private, err
+}
+
 type configReponse[T any] struct {
 	Config T `json:"config"`
 }
 
 // FetchGameConfig fetches the game config from the server.
+// Responses are cached in the REST cache shared by all sockets on the server.
 func FetchGameConfig[T any](socket *Socket, gameID string) (T, error) {
-	var config T
-	resp, err := http.Get(baseURL("http", socket.tls, "%s/api/games/%s", socket.gameURL, gameID))
+	var r configReponse[T]
+	data, err := sharedRESTCache(socket.gameURL).get("games/"+gameID, func() (any, error) {
+		return requestGame(socket.dialConfig.httpClient(), socket.gameURL, socket.tls, gameID)
+	})
+	if err != nil {
+		return r.Config, err
+	}
+	err = json.Unmarshal(data.([]byte), &r)
+	return r.Config, err
+}
+
+func requestGame(client *http.Client, trimmedURL string, tls bool, gameID string) ([]byte, error) {
+	resp, err := client.Get(baseURL("http", tls, "%s/api/games/%s", trimmedURL, gameID))
 	if err != nil {
-		return config, err
+		return nil, err
 	}
 	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 	if resp.StatusCode != http.StatusOK {
-		var data []byte
-		data, err = io.ReadAll(resp.Body)
-		if err == nil && len(data) > 0 {
-			return config, fmt.Errorf("failed to fetch game config: %s", string(data))
+		return nil, fmt.Errorf("failed to fetch game config: %w", newAPIError(resp.StatusCode, data))
+	}
+	return data, nil
+}
+
+// ServerInfo contains the information returned by the /api/info endpoint.
+type ServerInfo struct {
+	Name          string `json:"name"`
+	CGVersion     string `json:"cg_version"`
+	DisplayName   string `json:"display_name,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Version       string `json:"version,omitempty"`
+	RepositoryURL string `json:"repository_url,omitempty"`
+	// Multiplex is true if the server streams the events of several games over one connection
+	// at /api/spectate. See SpectateMultiplexed.
+	Multiplex bool `json:"multiplex,omitempty"`
+}
+
+func fetchInfo(client *http.Client, trimmedURL string, tls bool) (ServerInfo, error) {
+	resp, err := client.Get(baseURL("http", tls, "%s/api/info", trimmedURL))
+	if err != nil {
+		return ServerInfo{}, err
+	}
+	defer resp.Body.Close()
+	err = checkStatus(resp, "fetch server info", http.StatusOK)
+	if err != nil {
+		return ServerInfo{}, err
+	}
+
+	var info ServerInfo
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	return info, err
+}
+
+func fetchEvents(client *http.Client, trimmedURL string, tls bool) (string, error) {
+	resp, err := client.Get(baseURL("http", tls, "%s/api/events", trimmedURL))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch events: %w", newAPIError(resp.StatusCode, data))
+	}
+	return string(data), nil
+}
+
+// FetchEventsDefinition retrieves the CGE file of the game server at gameURL and parses it.
+// Downloaded files are cached locally by game name and version.
+// opts configure the connection to the server, e.g. with WithPinnedPublicKeys.
+func FetchEventsDefinition(gameURL string, opts ...DialOption) (*EventsDefinition, error) {
+	gameURL = trimURL(gameURL)
+	config := newDialConfig(gameURL, opts)
+	return fetchEventsDefinition(config.httpClient(), gameURL, probeTLS(gameURL, config))
+}
+
+func fetchEventsDefinition(client *http.Client, trimmedURL string, tls bool) (*EventsDefinition, error) {
+	info, err := fetchInfo(client, trimmedURL, tls)
+	if err != nil {
+		return nil, err
+	}
+
+	var cachePath string
+	if info.Name != "" && info.Version != "" {
+		dir, err := cacheDir("events", filepath.Base(info.Name))
+		if err == nil {
+			cachePath = filepath.Join(dir, filepath.Base(info.Version)+".cge")
+			if data, err := os.ReadFile(cachePath); err == nil {
+				return ParseCGE(string(data))
+			}
 		}
-		return config, fmt.Errorf("invalid response; expected: %d, got: %d", http.StatusOK, resp.StatusCode)
 	}
 
-	var r configReponse[T]
-	err = json.NewDecoder(resp.Body).Decode(&r)
-	return r.Config, err
+	source, err := fetchEvents(client, trimmedURL, tls)
+	if err != nil {
+		return nil, err
+	}
+
+	def, err := ParseCGE(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if cachePath != "" {
+		os.WriteFile(cachePath, []byte(source), 0o644)
+	}
+	return def, nil
 }
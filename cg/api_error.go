@@ -0,0 +1,83 @@
+package cg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Errors matched by APIError with errors.Is.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrServerError  = errors.New("server error")
+)
+
+// APIError is returned when the REST API of the server responds with an unexpected status code.
+type APIError struct {
+	StatusCode int
+	// Message is the error message returned by the server, if any.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether target is the sentinel error of the status code, e.g. ErrNotFound for 404.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrServerError:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// newAPIError parses the error body of a response.
+// The server returns either a JSON object with an error or message field or plain text.
+func newAPIError(statusCode int, body []byte) *APIError {
+	var r struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &r) == nil {
+		if r.Error != "" {
+			message = r.Error
+		} else if r.Message != "" {
+			message = r.Message
+		}
+	}
+	return &APIError{StatusCode: statusCode, Message: message}
+}
+
+// checkStatus returns an error wrapping an APIError if the status code of resp is not one of expected.
+// action describes the request, e.g. "fetch players".
+func checkStatus(resp *http.Response, action string, expected ...int) error {
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	return fmt.Errorf("failed to %s: %w", action, newAPIError(resp.StatusCode, data))
+}
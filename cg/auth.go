@@ -0,0 +1,102 @@
+package cg
+
+import (
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource supplies bearer tokens for servers that require token authentication.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token.
+type StaticToken string
+
+func (t StaticToken) Token() (string, error) {
+	return string(t), nil
+}
+
+// TokenSourceFunc is an adapter to allow the use of ordinary functions as token sources.
+type TokenSourceFunc func() (string, error)
+
+func (f TokenSourceFunc) Token() (string, error) {
+	return f()
+}
+
+// refreshBeforeExpiry is the time before the expiry at which RefreshingTokenSource fetches a new token.
+const refreshBeforeExpiry = 30 * time.Second
+
+type refreshingTokenSource struct {
+	lock   sync.Mutex
+	fetch  func() (token string, expiry time.Time, err error)
+	token  string
+	expiry time.Time
+}
+
+// RefreshingTokenSource caches the token returned by fetch and calls fetch again shortly before the token expires.
+func RefreshingTokenSource(fetch func() (token string, expiry time.Time, err error)) TokenSource {
+	return &refreshingTokenSource{fetch: fetch}
+}
+
+func (r *refreshingTokenSource) Token() (string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.token != "" && clock.Now().Add(refreshBeforeExpiry).Before(r.expiry) {
+		return r.token, nil
+	}
+	token, expiry, err := r.fetch()
+	if err != nil {
+		return "", err
+	}
+	r.token = token
+	r.expiry = expiry
+	return token, nil
+}
+
+// WithTokenSource makes the connection authenticate with a bearer token from ts.
+// The token is sent in the Authorization header of the websocket dial and of REST requests to the game server,
+// but never to other hosts. In the browser, where websocket requests cannot carry headers,
+// it is sent in the access_token query parameter of websocket URLs instead.
+func WithTokenSource(ts TokenSource) DialOption {
+	return func(config *dialConfig) {
+		config.tokenSource = ts
+	}
+}
+
+// bearerToken returns the current token of ts or an empty string if ts is nil.
+func bearerToken(ts TokenSource) (string, error) {
+	if ts == nil {
+		return "", nil
+	}
+	return ts.Token()
+}
+
+// addAccessToken appends the bearer token of ts to url if ts is not nil.
+// It is used where request headers cannot be set, e.g. in the browser.
+func addAccessToken(url string, ts TokenSource) (string, error) {
+	token, err := bearerToken(ts)
+	if err != nil || token == "" {
+		return url, err
+	}
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	return url + separator + "access_token=" + neturl.QueryEscape(token), nil
+}
+
+// addAuthorization sets the Authorization header to the bearer token of ts if ts is not nil.
+func addAuthorization(header http.Header, ts TokenSource) error {
+	token, err := bearerToken(ts)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
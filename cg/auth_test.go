@@ -0,0 +1,95 @@
+package cg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// newAuthTestServer starts a server recording the Authorization header of every request.
+// handler is called after the header has been recorded if it is not nil.
+func newAuthTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, func() []string) {
+	t.Helper()
+	var lock sync.Mutex
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		headers = append(headers, r.Header.Get("Authorization"))
+		lock.Unlock()
+		if handler != nil {
+			handler(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), headers...)
+	}
+}
+
+func TestTokenSourceIsScopedToGameHost(t *testing.T) {
+	foreign, foreignHeaders := newAuthTestServer(t, nil)
+	game, gameHeaders := newAuthTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, foreign.URL, http.StatusFound)
+		}
+	})
+	config := newDialConfig(trimURL(game.URL), []DialOption{WithTokenSource(StaticToken("secret"))})
+
+	for _, url := range []string{game.URL, foreign.URL, game.URL + "/redirect"} {
+		resp, err := config.httpClient().Get(url)
+		if err != nil {
+			t.Fatalf("GET %s: %s", url, err)
+		}
+		resp.Body.Close()
+	}
+	resp, err := httpClient.Get(game.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got := gameHeaders(); len(got) != 3 || got[0] != "Bearer secret" || got[1] != "Bearer secret" || got[2] != "" {
+		t.Errorf("expected the token only on requests of the socket client to the game server, got %q", got)
+	}
+	for _, header := range foreignHeaders() {
+		if header != "" {
+			t.Errorf("expected no Authorization header on the foreign server, got %q", header)
+		}
+	}
+	if n := len(foreignHeaders()); n != 2 {
+		t.Errorf("expected 2 requests to the foreign server, got %d", n)
+	}
+}
+
+func TestTokenSourceAuthenticatesWebsocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	game, headers := newAuthTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err == nil {
+			conn.Close()
+		}
+	})
+	url := "ws" + strings.TrimPrefix(game.URL, "http")
+
+	config := newDialConfig(trimURL(game.URL), []DialOption{WithTokenSource(StaticToken("secret"))})
+	conn, err := dialWebsocket(url, config)
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	conn.Close()
+	conn, err = dialWebsocket(url, newDialConfig(trimURL(game.URL), nil))
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	conn.Close()
+
+	if got := headers(); len(got) != 2 || got[0] != "Bearer secret" || got[1] != "" {
+		t.Errorf("expected the token only with WithTokenSource, got %q", got)
+	}
+}
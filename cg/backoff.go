@@ -0,0 +1,57 @@
+package cg
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// BackoffStrategy determines the delay before retry attempts.
+type BackoffStrategy interface {
+	// NextDelay returns the delay before attempt, starting at 1.
+	NextDelay(attempt int) time.Duration
+}
+
+// ConstantBackoff waits the same duration before every attempt.
+type ConstantBackoff time.Duration
+
+func (b ConstantBackoff) NextDelay(attempt int) time.Duration {
+	return time.Duration(b)
+}
+
+// ExponentialBackoff multiplies the delay after every attempt.
+type ExponentialBackoff struct {
+	// Initial is the delay before the first attempt.
+	Initial time.Duration
+	// Max caps the delay. 0 means no limit.
+	Max time.Duration
+	// Multiplier is the factor the delay grows by after every attempt. Defaults to 2.
+	Multiplier float64
+	// Jitter randomizes every delay by up to the given fraction in either direction, e.g. 0.2 for ±20%,
+	// so that many clients do not retry at the same time.
+	Jitter float64
+}
+
+func (b ExponentialBackoff) NextDelay(attempt int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(attempt-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		delay += delay * b.Jitter * (2*rand.Float64() - 1)
+	}
+	if delay < 0 {
+		return 0
+	}
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,64 @@
+package cg
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// SlowConsumerEvent is generated locally when the event queue reaches the high watermark set with SetBackpressure.
+// Its data is SlowConsumer.
+const SlowConsumerEvent EventName = "cg_slow_consumer"
+
+// SlowConsumer is the data of SlowConsumerEvent.
+type SlowConsumer struct {
+	// QueueDepth is the number of queued events when reading was paused.
+	QueueDepth int `json:"queue_depth"`
+	Capacity   int `json:"capacity"`
+}
+
+// backpressurePollInterval is the interval in which a paused listen loop checks whether the queue has drained.
+const backpressurePollInterval = 10 * time.Millisecond
+
+// SetBackpressure makes the socket pause reading from the connection once high events are queued
+// (see WithEventBufferSize) and resume when at most low events are left.
+// When reading is paused a SlowConsumerEvent is queued. The read deadline is extended while paused,
+// so a slow application does not cause a heartbeat or read timeout, and the server is slowed down by TCP flow control.
+// Without backpressure the listen loop blocks on a full queue instead. Passing high <= 0 disables backpressure.
+func (s *Socket) SetBackpressure(high, low int) {
+	if low > high {
+		low = high
+	}
+	atomic.StoreInt32(&s.lowWatermark, int32(low))
+	atomic.StoreInt32(&s.highWatermark, int32(high))
+}
+
+// WithBackpressure is equivalent to calling SetBackpressure after connecting.
+func WithBackpressure(high, low int) Option {
+	return func(config *socketConfig) {
+		config.highWatermark = high
+		config.lowWatermark = low
+	}
+}
+
+// applyBackpressure blocks the listen loop of generation while eventChan is above the high watermark
+// until it has drained to the low watermark.
+func (s *Socket) applyBackpressure(wsConn wsConnection, eventChan chan Event, generation int) {
+	high := int(atomic.LoadInt32(&s.highWatermark))
+	if high <= 0 || len(eventChan) < high {
+		return
+	}
+
+	s.triggerOverflow(len(eventChan), cap(eventChan))
+	data, err := json.Marshal(SlowConsumer{QueueDepth: len(eventChan), Capacity: cap(eventChan)})
+	if err == nil {
+		s.enqueue(eventChan, Event{Name: SlowConsumerEvent, Data: data})
+	}
+	s.logf("cg: event queue reached %d/%d events, pausing reads", len(eventChan), cap(eventChan))
+
+	for s.isActive(generation) && len(eventChan) > int(atomic.LoadInt32(&s.lowWatermark)) {
+		s.extendReadDeadline(wsConn)
+		clock.Sleep(backpressurePollInterval)
+	}
+	s.extendReadDeadline(wsConn)
+}
@@ -0,0 +1,307 @@
+package cg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// EventsDefinition is the parsed model of a CGE (CodeGame events) file.
+type EventsDefinition struct {
+	Name       string
+	CGEVersion string
+	Config     *ObjectDefinition
+	Commands   []ObjectDefinition
+	Events     []ObjectDefinition
+	Types      []ObjectDefinition
+	Enums      []EnumDefinition
+}
+
+// ObjectDefinition describes a config, command, event or type declaration.
+type ObjectDefinition struct {
+	Name       string
+	Doc        string
+	Properties []PropertyDefinition
+}
+
+// PropertyDefinition describes a single property of an object, e.g. `message: string`.
+// Type is the raw CGE type, e.g. `list<map<int>>`.
+type PropertyDefinition struct {
+	Name string
+	Type string
+	Doc  string
+}
+
+// EnumDefinition describes an enum declaration.
+type EnumDefinition struct {
+	Name   string
+	Doc    string
+	Values []EnumValue
+}
+
+type EnumValue struct {
+	Name string
+	Doc  string
+}
+
+// Event returns the definition of the event with the specified name.
+func (d *EventsDefinition) Event(name EventName) (ObjectDefinition, bool) {
+	return findObject(d.Events, string(name))
+}
+
+// Command returns the definition of the command with the specified name.
+func (d *EventsDefinition) Command(name CommandName) (ObjectDefinition, bool) {
+	return findObject(d.Commands, string(name))
+}
+
+func findObject(objects []ObjectDefinition, name string) (ObjectDefinition, bool) {
+	for _, o := range objects {
+		if o.Name == name {
+			return o, true
+		}
+	}
+	return ObjectDefinition{}, false
+}
+
+// ParseCGE parses the content of a CGE file.
+func ParseCGE(source string) (*EventsDefinition, error) {
+	p := &cgeParser{
+		tokens: tokenizeCGE(source),
+		def:    &EventsDefinition{},
+	}
+	err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CGE: %w", err)
+	}
+	return p.def, nil
+}
+
+type cgeToken struct {
+	text string
+	doc  string
+	line int
+}
+
+func tokenizeCGE(source string) []cgeToken {
+	var tokens []cgeToken
+	var doc []string
+	for i, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "//") {
+			doc = append(doc, strings.TrimSpace(strings.TrimPrefix(trimmed, "//")))
+			continue
+		}
+		line, _, _ = strings.Cut(line, "//")
+
+		start := -1
+		emit := func(text string) {
+			tokens = append(tokens, cgeToken{text: text, doc: strings.Join(doc, "\n"), line: i + 1})
+			doc = nil
+		}
+		for j, r := range line {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' {
+				if start < 0 {
+					start = j
+				}
+				continue
+			}
+			if start >= 0 {
+				emit(line[start:j])
+				start = -1
+			}
+			if !unicode.IsSpace(r) {
+				emit(string(r))
+			}
+		}
+		if start >= 0 {
+			emit(line[start:])
+		}
+	}
+	return tokens
+}
+
+type cgeParser struct {
+	tokens []cgeToken
+	pos    int
+	def    *EventsDefinition
+}
+
+func (p *cgeParser) peek() (cgeToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return cgeToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *cgeParser) next() (cgeToken, error) {
+	t, ok := p.peek()
+	if !ok {
+		return cgeToken{}, errors.New("unexpected end of file")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *cgeParser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.text != text {
+		return fmt.Errorf("line %d: expected '%s', got '%s'", t.line, text, t.text)
+	}
+	return nil
+}
+
+func (p *cgeParser) parse() error {
+	for {
+		t, ok := p.peek()
+		if !ok {
+			return nil
+		}
+		p.pos++
+		switch t.text {
+		case "name":
+			name, err := p.next()
+			if err != nil {
+				return err
+			}
+			p.def.Name = name.text
+		case "version":
+			version, err := p.next()
+			if err != nil {
+				return err
+			}
+			p.def.CGEVersion = version.text
+		case "config":
+			obj, err := p.parseObject("config", t.doc)
+			if err != nil {
+				return err
+			}
+			p.def.Config = &obj
+		case "command", "event", "type":
+			name, err := p.next()
+			if err != nil {
+				return err
+			}
+			obj, err := p.parseObject(name.text, t.doc)
+			if err != nil {
+				return err
+			}
+			switch t.text {
+			case "command":
+				p.def.Commands = append(p.def.Commands, obj)
+			case "event":
+				p.def.Events = append(p.def.Events, obj)
+			default:
+				p.def.Types = append(p.def.Types, obj)
+			}
+		case "enum":
+			name, err := p.next()
+			if err != nil {
+				return err
+			}
+			err = p.parseEnum(name.text, t.doc)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("line %d: unexpected '%s'", t.line, t.text)
+		}
+	}
+}
+
+func (p *cgeParser) parseObject(name, doc string) (ObjectDefinition, error) {
+	obj := ObjectDefinition{Name: name, Doc: doc}
+	err := p.expect("{")
+	if err != nil {
+		return obj, err
+	}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return obj, err
+		}
+		switch t.text {
+		case "}":
+			return obj, nil
+		case ",":
+			continue
+		}
+		err = p.expect(":")
+		if err != nil {
+			return obj, err
+		}
+		typ, err := p.parseType()
+		if err != nil {
+			return obj, err
+		}
+		obj.Properties = append(obj.Properties, PropertyDefinition{Name: t.text, Type: typ, Doc: t.doc})
+	}
+}
+
+// parseType parses a property type. Inline type and enum declarations are added to the definition.
+func (p *cgeParser) parseType() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	switch t.text {
+	case "type":
+		name, err := p.next()
+		if err != nil {
+			return "", err
+		}
+		obj, err := p.parseObject(name.text, t.doc)
+		if err != nil {
+			return "", err
+		}
+		p.def.Types = append(p.def.Types, obj)
+		return name.text, nil
+	case "enum":
+		name, err := p.next()
+		if err != nil {
+			return "", err
+		}
+		return name.text, p.parseEnum(name.text, t.doc)
+	case "list", "map":
+		err = p.expect("<")
+		if err != nil {
+			return "", err
+		}
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		err = p.expect(">")
+		if err != nil {
+			return "", err
+		}
+		return t.text + "<" + inner + ">", nil
+	default:
+		return t.text, nil
+	}
+}
+
+func (p *cgeParser) parseEnum(name, doc string) error {
+	enum := EnumDefinition{Name: name, Doc: doc}
+	err := p.expect("{")
+	if err != nil {
+		return err
+	}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch t.text {
+		case "}":
+			p.def.Enums = append(p.def.Enums, enum)
+			return nil
+		case ",":
+			continue
+		}
+		enum.Values = append(enum.Values, EnumValue{Name: t.text, Doc: t.doc})
+	}
+}
@@ -0,0 +1,197 @@
+package cg_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+const testCGE = `name test_game
+version 0.4
+
+// The configuration.
+config {
+	width: int, // The trailing comment is ignored.
+}
+
+// Sent by the server.
+// Spans two lines.
+event moved {
+	// The new position.
+	pos: type position {
+		x: int,
+		y: int
+	},
+	path: list<position>,
+	scores: map<list<int>>,
+	direction: enum direction { north, south }
+}
+
+command move {
+	direction: direction
+}
+`
+
+func TestParseCGE(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected *cg.EventsDefinition
+	}{
+		{name: "empty", source: "", expected: &cg.EventsDefinition{}},
+		{name: "only comments", source: "// nothing\n  // to see here\n", expected: &cg.EventsDefinition{}},
+		{
+			name:   "header",
+			source: "name foo // comment\nversion 1.2",
+			expected: &cg.EventsDefinition{
+				Name:       "foo",
+				CGEVersion: "1.2",
+			},
+		},
+		{
+			name:   "documented enum",
+			source: "// Colors.\nenum color {\n\t// The first one.\n\tred,\n\tgreen\n}",
+			expected: &cg.EventsDefinition{
+				Enums: []cg.EnumDefinition{{Name: "color", Doc: "Colors.", Values: []cg.EnumValue{
+					{Name: "red", Doc: "The first one."},
+					{Name: "green"},
+				}}},
+			},
+		},
+		{
+			name:   "full definition",
+			source: testCGE,
+			expected: &cg.EventsDefinition{
+				Name:       "test_game",
+				CGEVersion: "0.4",
+				Config: &cg.ObjectDefinition{Name: "config", Doc: "The configuration.", Properties: []cg.PropertyDefinition{
+					{Name: "width", Type: "int"},
+				}},
+				Commands: []cg.ObjectDefinition{{Name: "move", Properties: []cg.PropertyDefinition{
+					{Name: "direction", Type: "direction"},
+				}}},
+				Events: []cg.ObjectDefinition{{Name: "moved", Doc: "Sent by the server.\nSpans two lines.", Properties: []cg.PropertyDefinition{
+					{Name: "pos", Type: "position", Doc: "The new position."},
+					{Name: "path", Type: "list<position>"},
+					{Name: "scores", Type: "map<list<int>>"},
+					{Name: "direction", Type: "direction"},
+				}}},
+				Types: []cg.ObjectDefinition{{Name: "position", Properties: []cg.PropertyDefinition{
+					{Name: "x", Type: "int"},
+					{Name: "y", Type: "int"},
+				}}},
+				Enums: []cg.EnumDefinition{{Name: "direction", Values: []cg.EnumValue{{Name: "north"}, {Name: "south"}}}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			def, err := cg.ParseCGE(test.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(def, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, def)
+			}
+		})
+	}
+}
+
+func TestParseCGEErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		// err is the expected error message without the common prefix.
+		err string
+	}{
+		{name: "unknown declaration", source: "name foo\n\nfoo bar", err: "line 3: unexpected 'foo'"},
+		{name: "missing brace", source: "config\n\twidth: int\n}", err: "line 2: expected '{', got 'width'"},
+		{name: "missing colon", source: "event foo {\n\tx int\n}", err: "line 2: expected ':', got 'int'"},
+		{name: "unclosed generic", source: "event foo {\n\tx: list<int\n}", err: "line 3: expected '>', got '}'"},
+		{name: "missing generic", source: "command foo {\n\tx: map int\n}", err: "line 2: expected '<', got 'int'"},
+		{name: "unclosed object", source: "event foo {\n\tx: int,", err: "unexpected end of file"},
+		{name: "unclosed enum", source: "enum foo { a, b", err: "unexpected end of file"},
+		{name: "missing name", source: "name", err: "unexpected end of file"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := cg.ParseCGE(test.source)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if expected := "failed to parse CGE: " + test.err; err.Error() != expected {
+				t.Errorf("expected %q, got %q", expected, err)
+			}
+		})
+	}
+}
+
+func TestEventsDefinitionLookup(t *testing.T) {
+	def, err := cg.ParseCGE(testCGE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event, ok := def.Event("moved"); !ok || len(event.Properties) != 4 {
+		t.Errorf("expected to find event moved, got %+v", event)
+	}
+	if _, ok := def.Event("move"); ok {
+		t.Error("expected commands not to be returned as events")
+	}
+	if command, ok := def.Command("move"); !ok || command.Name != "move" {
+		t.Errorf("expected to find command move, got %+v", command)
+	}
+}
+
+func TestFetchEventsDefinitionCache(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	t.Setenv("HOME", cacheHome)
+
+	var version atomic.Value
+	version.Store("1.0.0")
+	var downloads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/info":
+			fmt.Fprintf(w, `{"name":"cge_test","cg_version":"0.7","version":%q}`, version.Load())
+		case "/api/events":
+			atomic.AddInt32(&downloads, 1)
+			w.Write([]byte(testCGE))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetch := func() {
+		t.Helper()
+		def, err := cg.FetchEventsDefinition(server.URL)
+		if err != nil {
+			t.Fatalf("failed to fetch events definition: %s", err)
+		}
+		if def.Name != "test_game" {
+			t.Fatalf("expected the definition of test_game, got %q", def.Name)
+		}
+	}
+
+	fetch()
+	if n := atomic.LoadInt32(&downloads); n != 1 {
+		t.Fatalf("expected a cache miss to download the file, got %d downloads", n)
+	}
+	fetch()
+	if n := atomic.LoadInt32(&downloads); n != 1 {
+		t.Fatalf("expected a cache hit not to download the file, got %d downloads", n)
+	}
+
+	// Every game version is cached separately.
+	version.Store("1.1.0")
+	fetch()
+	if n := atomic.LoadInt32(&downloads); n != 2 {
+		t.Fatalf("expected a new version to download the file, got %d downloads", n)
+	}
+}
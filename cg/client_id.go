@@ -0,0 +1,112 @@
+package cg
+
+import (
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const modulePath = "github.com/code-game-project/go-client"
+
+var (
+	userAgentLock sync.RWMutex
+	userAgent     string
+)
+
+// SetUserAgent sets the User-Agent header sent with all websocket dials and REST requests.
+// An empty string restores the default User-Agent of net/http.
+func SetUserAgent(ua string) {
+	userAgentLock.Lock()
+	userAgent = ua
+	userAgentLock.Unlock()
+}
+
+var (
+	versionOnce sync.Once
+	version     string
+)
+
+// libraryVersion returns the version of this module as recorded in the build info of the binary.
+func libraryVersion() string {
+	versionOnce.Do(func() {
+		version = "unknown"
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		if info.Main.Path == modulePath {
+			version = info.Main.Version
+			return
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+				return
+			}
+		}
+	})
+	return version
+}
+
+// clientHeader returns the value of the X-CodeGame-Client header, e.g. `go-client/v0.9.2 (CodeGame v0.8)`.
+func clientHeader() string {
+	return "go-client/" + libraryVersion() + " (CodeGame v" + CGVersion + ")"
+}
+
+// requestHeaders returns the client identification headers.
+func requestHeaders() http.Header {
+	header := http.Header{}
+	header.Set("X-CodeGame-Client", clientHeader())
+	userAgentLock.RLock()
+	if userAgent != "" {
+		header.Set("User-Agent", userAgent)
+	}
+	userAgentLock.RUnlock()
+	return header
+}
+
+// headerTransport adds the client identification headers to every request
+// and the bearer token of tokenSource to requests to host.
+type headerTransport struct {
+	base        http.RoundTripper
+	tokenSource TokenSource
+	host        string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range requestHeaders() {
+		req.Header[key] = values
+	}
+	if t.tokenSource != nil && req.URL.Host == t.host {
+		err := addAuthorization(req.Header, t.tokenSource)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// httpClient is used for REST requests unless the dial options require a dedicated client. See dialConfig.httpClient.
+var httpClient = &http.Client{
+	Transport: headerTransport{base: newHTTPTransport()},
+}
+
+// outboundClient is used for requests to services other than the game server, e.g. webhooks.
+// It adds neither the client identification headers nor credentials.
+var outboundClient = &http.Client{
+	Transport: newHTTPTransport(),
+}
+
+// newHTTPTransport returns a copy of http.DefaultTransport that can also dial unix domain sockets.
+func newHTTPTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFromEnvironment
+	transport.DialContext = unixAwareDialer((&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext)
+	return transport
+}
@@ -0,0 +1,36 @@
+package cg
+
+import "time"
+
+// Clock abstracts time so that timeouts, backoff and other timing logic can be tested deterministically.
+// Network deadlines always use the real time.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+var clock Clock = realClock{}
+
+// SetClock replaces the clock used by the package. Passing nil restores the real clock.
+// SetClock should be called before any socket is created.
+func SetClock(c Clock) {
+	if c == nil {
+		c = realClock{}
+	}
+	clock = c
+}
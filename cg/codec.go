@@ -0,0 +1,33 @@
+package cg
+
+import "encoding/json"
+
+// Codec encodes and decodes JSON.
+// It allows replacing encoding/json with faster implementations like jsoniter, go-json or sonic.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type stdCodec struct{}
+
+func (stdCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+var codec Codec = stdCodec{}
+
+// SetCodec replaces the JSON implementation used for events, commands and debug messages.
+// Passing nil restores encoding/json.
+// SetCodec should be called before any socket is created.
+// Strict decoding always uses encoding/json.
+func SetCodec(c Codec) {
+	if c == nil {
+		c = stdCodec{}
+	}
+	codec = c
+}
@@ -0,0 +1,50 @@
+package cg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+// aliasingCodec decodes like encoding/json but returns event data that aliases the decoded message.
+type aliasingCodec struct{}
+
+func (aliasingCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (aliasingCodec) Unmarshal(data []byte, v any) error {
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		return err
+	}
+	if event, ok := v.(*cg.Event); ok && len(event.Data) > 0 {
+		if i := bytes.Index(data, event.Data); i >= 0 {
+			event.Data = data[i : i+len(event.Data)]
+		}
+	}
+	return nil
+}
+
+func TestCodecAliasingEventData(t *testing.T) {
+	cg.SetCodec(aliasingCodec{})
+	defer cg.SetCodec(nil)
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+
+	server.Emit("move", map[string]string{"direction": "up"})
+	server.Emit("move", map[string]string{"direction": "xx"})
+	first := cgtest.ExpectEvent(t, socket, "move", time.Second)
+	second := cgtest.ExpectEvent(t, socket, "move", time.Second)
+
+	if string(first.Data) != `{"direction":"up"}` {
+		t.Errorf("expected the data of the first event to be retained, got %s", first.Data)
+	}
+	if string(second.Data) != `{"direction":"xx"}` {
+		t.Errorf("expected the data of the second event, got %s", second.Data)
+	}
+}
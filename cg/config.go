@@ -0,0 +1,108 @@
+package cg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ColorMode controls whether tools built on this package use colored output.
+type ColorMode string
+
+const (
+	// ColorAuto enables colors if the output is a terminal.
+	ColorAuto   ColorMode = "auto"
+	ColorAlways ColorMode = "always"
+	ColorNever  ColorMode = "never"
+)
+
+// ClientConfig contains defaults shared by all CodeGame tools built on this package.
+// It is stored in codegame/config.json in the user config directory, e.g. ~/.config/codegame/config.json on Linux.
+type ClientConfig struct {
+	// Username is the preferred username for new players.
+	Username string `json:"username,omitempty"`
+	// GameURL is the default game server.
+	GameURL string `json:"game_url,omitempty"`
+	// Color defaults to ColorAuto.
+	Color ColorMode `json:"color,omitempty"`
+	// DataDir overrides the directory used for persistent files like sessions.
+	DataDir string `json:"data_dir,omitempty"`
+}
+
+// ConfigPath returns the path of the config file. It can be overridden with the CG_CONFIG environment variable.
+func ConfigPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "codegame", "config.json"), nil
+}
+
+// LoadConfig reads the config file. A missing file results in an empty config.
+func LoadConfig() (ClientConfig, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return ClientConfig{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ClientConfig{}, nil
+		}
+		return ClientConfig{}, err
+	}
+	var config ClientConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return ClientConfig{}, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Save writes c to the config file.
+func (c ClientConfig) Save() error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return ErrEncodeFailed
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+var (
+	configLock   sync.Mutex
+	activeConfig *ClientConfig
+)
+
+// Config returns the active config. The config file is loaded on first use unless SetConfig has been called.
+// Errors while loading the file result in an empty config.
+func Config() ClientConfig {
+	configLock.Lock()
+	defer configLock.Unlock()
+	if activeConfig == nil {
+		config, _ := LoadConfig()
+		activeConfig = &config
+	}
+	return *activeConfig
+}
+
+// SetConfig overrides the active config for this process, e.g. with values from command line flags.
+// The config file is not modified.
+func SetConfig(config ClientConfig) {
+	configLock.Lock()
+	activeConfig = &config
+	configLock.Unlock()
+}
@@ -0,0 +1,72 @@
+package cg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ConnectError is returned by Dial, Connect and Spectate when establishing the connection fails.
+type ConnectError struct {
+	// Stage is the stage that failed.
+	Stage ConnectStage
+	// StatusCode is the HTTP status code returned by the server or 0 if no response was received.
+	StatusCode int
+	// Body is the error message returned by the server, if any.
+	Body string
+	Err  error
+}
+
+func (e *ConnectError) Error() string {
+	msg := fmt.Sprintf("%s failed", strings.ToLower(e.Stage.String()))
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" with status %d", e.StatusCode)
+	}
+	if e.Body != "" {
+		msg += ": " + e.Body
+	} else if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return Redact(msg)
+}
+
+func (e *ConnectError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the sentinel errors of APIError, e.g. ErrUnauthorized if the server rejected the handshake with 401.
+func (e *ConnectError) Is(target error) bool {
+	return e.StatusCode != 0 && (&APIError{StatusCode: e.StatusCode}).Is(target)
+}
+
+// newConnectError wraps err in a ConnectError for stage unless it already is one.
+// The status code and message of an APIError in the chain of err are copied.
+func newConnectError(stage ConnectStage, err error) error {
+	if _, ok := err.(*ConnectError); ok {
+		return err
+	}
+	connectErr := &ConnectError{Stage: stage, Err: err}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		connectErr.StatusCode = apiErr.StatusCode
+		connectErr.Body = apiErr.Message
+	}
+	return connectErr
+}
+
+// handshakeError creates a ConnectError from the response to a failed websocket handshake.
+// resp may be nil.
+func handshakeError(resp *http.Response, err error) error {
+	connectErr := &ConnectError{Stage: StageWebsocket, Err: redactError(err)}
+	if resp != nil {
+		connectErr.StatusCode = resp.StatusCode
+		if resp.Body != nil {
+			data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+			connectErr.Body = newAPIError(resp.StatusCode, data).Message
+		}
+	}
+	return connectErr
+}
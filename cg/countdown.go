@@ -0,0 +1,153 @@
+package cg
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Countdown fires a callback when a deadline announced by the server expires.
+type Countdown struct {
+	lock     sync.Mutex
+	deadline time.Time
+	stopped  bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// StartCountdown starts a countdown for a duration received from the server.
+// Half of rtt is subtracted because the duration was measured when the server sent the event.
+// callback is invoked from a separate goroutine.
+func StartCountdown(remaining, rtt time.Duration, callback func()) *Countdown {
+	return startCountdown(clock.Now().Add(remaining-rtt/2), callback)
+}
+
+// StartCountdownUntil starts a countdown for a deadline received from the server.
+// offset is the difference between the server clock and the local clock (server - local).
+// callback is invoked from a separate goroutine.
+func StartCountdownUntil(deadline time.Time, offset time.Duration, callback func()) *Countdown {
+	return startCountdown(deadline.Add(-offset), callback)
+}
+
+func startCountdown(deadline time.Time, callback func()) *Countdown {
+	c := &Countdown{
+		deadline: deadline,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go func() {
+		select {
+		case <-clock.After(deadline.Sub(clock.Now())):
+		case <-c.stop:
+			return
+		}
+		c.lock.Lock()
+		if c.stopped {
+			c.lock.Unlock()
+			return
+		}
+		c.stopped = true
+		close(c.done)
+		c.lock.Unlock()
+		callback()
+	}()
+	return c
+}
+
+// Deadline returns the local time at which the countdown expires.
+func (c *Countdown) Deadline() time.Time {
+	return c.deadline
+}
+
+// Remaining returns the time left until the countdown expires or 0 if it has expired.
+func (c *Countdown) Remaining() time.Duration {
+	remaining := c.deadline.Sub(clock.Now())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Done returns a channel that is closed when the countdown expires. It is never closed if the countdown is stopped.
+func (c *Countdown) Done() <-chan struct{} {
+	return c.done
+}
+
+// Stop prevents the callback from being invoked.
+// It returns false if the countdown has already expired or been stopped.
+func (c *Countdown) Stop() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.stopped {
+		return false
+	}
+	c.stopped = true
+	close(c.stop)
+	return true
+}
+
+// MeasureLatency estimates the round-trip time to the server and the offset of the server clock
+// using a request to /api/info. The offset is derived from the Date header and therefore only accurate to about a second.
+// The results are stored in the socket and used by OnCountdown.
+func (s *Socket) MeasureLatency(ctx context.Context) (rtt, offset time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL("http", s.tls, "%s/api/info", s.gameURL), nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	start := clock.Now()
+	resp, err := s.dialConfig.httpClient().Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	resp.Body.Close()
+	rtt = clock.Now().Sub(start)
+
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		offset = date.Sub(start.Add(rtt / 2))
+	}
+	atomic.StoreInt64(&s.rtt, int64(rtt))
+	atomic.StoreInt64(&s.clockOffset, int64(offset))
+	return rtt, offset, nil
+}
+
+// OnCountdown starts a countdown whenever event is received and invokes callback with the event when it expires.
+// field is the name of the property of the event data containing either the remaining time in milliseconds (number)
+// or the deadline as an RFC 3339 timestamp (string).
+// A new event replaces the running countdown. The values measured by MeasureLatency are used for correction.
+// callback is invoked from a separate goroutine.
+func (s *Socket) OnCountdown(event EventName, field string, callback func(event Event)) CallbackID {
+	var lock sync.Mutex
+	var current *Countdown
+	return s.On(event, func(e Event) {
+		var data map[string]json.RawMessage
+		err := e.UnmarshalData(&data)
+		if err != nil {
+			return
+		}
+		fire := func() {
+			callback(e)
+		}
+
+		var countdown *Countdown
+		var millis float64
+		var deadline time.Time
+		if json.Unmarshal(data[field], &millis) == nil {
+			countdown = StartCountdown(time.Duration(millis*float64(time.Millisecond)), time.Duration(atomic.LoadInt64(&s.rtt)), fire)
+		} else if json.Unmarshal(data[field], &deadline) == nil {
+			countdown = StartCountdownUntil(deadline, time.Duration(atomic.LoadInt64(&s.clockOffset)), fire)
+		} else {
+			s.logf("cg: event '%s' has no valid countdown field '%s'", e.Name, field)
+			return
+		}
+
+		lock.Lock()
+		if current != nil {
+			current.Stop()
+		}
+		current = countdown
+		lock.Unlock()
+	})
+}
@@ -0,0 +1,49 @@
+package cg_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestMeasureLatencyDuringCountdowns(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+	var fired int32
+	socket.OnCountdown("round", "remaining", func(cg.Event) {
+		atomic.AddInt32(&fired, 1)
+	})
+	loopDone := make(chan struct{})
+	go func() {
+		socket.RunEventLoop()
+		close(loopDone)
+	}()
+
+	measured := make(chan error)
+	go func() {
+		for i := 0; i < 10; i++ {
+			_, _, err := socket.MeasureLatency(context.Background())
+			if err != nil {
+				measured <- err
+				return
+			}
+		}
+		measured <- nil
+	}()
+	for i := 0; i < 10; i++ {
+		server.Emit("round", map[string]any{"remaining": 0})
+		server.Emit("round", map[string]any{"remaining": time.Now().UTC().Format(time.RFC3339Nano)})
+	}
+	if err := <-measured; err != nil {
+		t.Fatalf("MeasureLatency failed: %s", err)
+	}
+	waitFor(t, "countdown", func() bool {
+		return atomic.LoadInt32(&fired) > 0
+	})
+	socket.Close()
+	<-loopDone
+}
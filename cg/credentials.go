@@ -0,0 +1,76 @@
+package cg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrNoCredentials = errors.New("no credentials found")
+
+// CredentialsProvider supplies player secrets, e.g. from environment variables or a secret manager.
+type CredentialsProvider interface {
+	// GetPlayerSecret returns the secret of the player or ErrNoCredentials if the provider has none.
+	GetPlayerSecret(gameURL, gameID, playerID string) (string, error)
+}
+
+// CredentialsProviderFunc is an adapter to allow the use of ordinary functions as credentials providers.
+type CredentialsProviderFunc func(gameURL, gameID, playerID string) (string, error)
+
+func (f CredentialsProviderFunc) GetPlayerSecret(gameURL, gameID, playerID string) (string, error) {
+	return f(gameURL, gameID, playerID)
+}
+
+// EnvCredentials reads the player secret from the environment variable Name.
+// If Name is empty, EnvPlayerSecret is used.
+type EnvCredentials struct {
+	Name string
+}
+
+func (e EnvCredentials) GetPlayerSecret(gameURL, gameID, playerID string) (string, error) {
+	name := e.Name
+	if name == "" {
+		name = EnvPlayerSecret
+	}
+	secret, ok := os.LookupEnv(name)
+	if !ok || secret == "" {
+		return "", ErrNoCredentials
+	}
+	return secret, nil
+}
+
+// StaticCredentials maps player IDs to player secrets.
+type StaticCredentials map[string]string
+
+func (s StaticCredentials) GetPlayerSecret(gameURL, gameID, playerID string) (string, error) {
+	secret, ok := s[playerID]
+	if !ok {
+		return "", ErrNoCredentials
+	}
+	return secret, nil
+}
+
+// ChainCredentials queries each provider in order and returns the first secret found.
+type ChainCredentials []CredentialsProvider
+
+func (c ChainCredentials) GetPlayerSecret(gameURL, gameID, playerID string) (string, error) {
+	for _, provider := range c {
+		secret, err := provider.GetPlayerSecret(gameURL, gameID, playerID)
+		if err == nil {
+			return secret, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			return "", err
+		}
+	}
+	return "", ErrNoCredentials
+}
+
+// ConnectWithCredentials connects to the game as playerID using the secret supplied by provider.
+func ConnectWithCredentials(gameURL, gameID, playerID string, provider CredentialsProvider) (*Socket, error) {
+	secret, err := provider.GetPlayerSecret(gameURL, gameID, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret of player %s: %w", playerID, err)
+	}
+	return Connect(gameURL, gameID, playerID, secret)
+}
@@ -0,0 +1,55 @@
+package cg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// errDeadLetter is returned by receiveEvent when an undecodable message has been captured and should be skipped.
+var errDeadLetter = errors.New("message captured as dead letter")
+
+// DeadLetterHandler receives messages that could not be decoded into an event.
+// raw is only valid until the handler returns.
+type DeadLetterHandler func(raw []byte, err error)
+
+// SetDeadLetterHandler registers handler to receive undecodable messages.
+// While a handler or a capture directory is set, such messages are skipped instead of closing the connection.
+// The handler is invoked from the listen goroutine. Pass nil to remove the handler.
+func (s *Socket) SetDeadLetterHandler(handler DeadLetterHandler) {
+	s.deadLetterHandler = handler
+}
+
+// SetDeadLetterDir makes the socket write every undecodable message to a new file in dir.
+// Each file contains the decode error followed by an empty line and the raw message.
+// While a handler or a capture directory is set, such messages are skipped instead of closing the connection.
+// Pass an empty string to disable capturing.
+func (s *Socket) SetDeadLetterDir(dir string) error {
+	if dir != "" {
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return err
+		}
+	}
+	s.deadLetterDir = dir
+	return nil
+}
+
+// captureDeadLetter passes msg to the dead-letter handler and capture directory.
+// It returns false if dead-letter capture is disabled.
+func (s *Socket) captureDeadLetter(msg []byte, err error) bool {
+	if s.deadLetterHandler == nil && s.deadLetterDir == "" {
+		return false
+	}
+	if s.deadLetterHandler != nil {
+		s.deadLetterHandler(msg, err)
+	}
+	if s.deadLetterDir != "" {
+		name := strconv.FormatInt(clock.Now().UnixNano(), 10) + ".dead"
+		content := append([]byte(fmt.Sprintf("%s\n\n", err)), msg...)
+		os.WriteFile(filepath.Join(s.deadLetterDir, name), content, 0o644)
+	}
+	return true
+}
@@ -0,0 +1,130 @@
+package cg
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DebugSource selects a debug stream of a server.
+// If GameID is empty, the server stream is used. If PlayerID is set, the stream of the player is used.
+type DebugSource struct {
+	// Name tags the messages of the stream. Defaults to "server", the game ID or "gameID/playerID".
+	Name         string
+	GameID       string
+	PlayerID     string
+	PlayerSecret string
+}
+
+func (s DebugSource) name() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.GameID == "":
+		return "server"
+	case s.PlayerID == "":
+		return s.GameID
+	default:
+		return s.GameID + "/" + s.PlayerID
+	}
+}
+
+// DebugFeedMessage is a debug message tagged with the name of its source.
+type DebugFeedMessage struct {
+	Source   string
+	Severity DebugSeverity
+	Message  string
+	// Data is empty if no data was included in the message.
+	Data string
+}
+
+// DebugFeed merges the debug streams of several games and players into a single channel.
+type DebugFeed struct {
+	sockets  []*DebugSocket
+	messages chan DebugFeedMessage
+	closing  chan struct{}
+	wg       sync.WaitGroup
+
+	closeOnce sync.Once
+}
+
+// severityLevel orders severities from trace (0) to error (3).
+func severityLevel(severity DebugSeverity) int {
+	switch severity {
+	case DebugTrace:
+		return 0
+	case DebugInfo:
+		return 1
+	case DebugWarning:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// WatchDebug streams the debug messages of all sources on the server at url with at least minSeverity.
+// When a stream ends because of an error, a message with severity error and the source's name is emitted.
+func WatchDebug(url string, sources []DebugSource, minSeverity DebugSeverity, opts ...DialOption) *DebugFeed {
+	f := &DebugFeed{
+		messages: make(chan DebugFeedMessage, 10*len(sources)),
+		closing:  make(chan struct{}),
+	}
+	level := severityLevel(minSeverity)
+	for _, source := range sources {
+		socket := NewDebugSocket(url, opts...)
+		socket.SetSeverities(level <= 0, level <= 1, level <= 2, true)
+		name := source.name()
+		socket.OnMessage(func(severity DebugSeverity, message, data string) {
+			if severityLevel(severity) >= level {
+				f.send(DebugFeedMessage{Source: name, Severity: severity, Message: message, Data: data})
+			}
+		})
+		f.sockets = append(f.sockets, socket)
+
+		f.wg.Add(1)
+		go func(source DebugSource) {
+			defer f.wg.Done()
+			var err error
+			switch {
+			case source.GameID == "":
+				err = socket.DebugServer()
+			case source.PlayerID == "":
+				err = socket.DebugGame(source.GameID)
+			default:
+				err = socket.DebugPlayer(source.GameID, source.PlayerID, source.PlayerSecret)
+			}
+			if err != ErrClosed {
+				f.send(DebugFeedMessage{Source: name, Severity: DebugError, Message: fmt.Sprintf("debug stream ended: %s", err)})
+			}
+		}(source)
+	}
+
+	go func() {
+		f.wg.Wait()
+		close(f.messages)
+	}()
+
+	return f
+}
+
+// Messages returns the merged message stream. The channel is closed once all streams have ended.
+func (f *DebugFeed) Messages() <-chan DebugFeedMessage {
+	return f.messages
+}
+
+// Close ends all debug streams.
+func (f *DebugFeed) Close() error {
+	f.closeOnce.Do(func() {
+		close(f.closing)
+		for _, socket := range f.sockets {
+			socket.Close()
+		}
+	})
+	return nil
+}
+
+func (f *DebugFeed) send(message DebugFeedMessage) {
+	select {
+	case f.messages <- message:
+	case <-f.closing:
+	}
+}
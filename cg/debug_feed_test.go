@@ -0,0 +1,57 @@
+package cg_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/gorilla/websocket"
+)
+
+func TestDebugFeedCloseDuringDial(t *testing.T) {
+	upgrading := make(chan struct{})
+	release := make(chan struct{})
+	connClosed := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/debug" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		close(upgrading)
+		<-release
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, _, err = conn.ReadMessage()
+		if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			t.Errorf("expected the connection to be closed by the client, got %v", err)
+		}
+		close(connClosed)
+	}))
+	defer server.Close()
+
+	feed := cg.WatchDebug(server.URL, []cg.DebugSource{{}}, cg.DebugInfo)
+	<-upgrading
+	feed.Close()
+	close(release)
+
+	select {
+	case <-connClosed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected the connection established after Close to be closed")
+	}
+	select {
+	case message, ok := <-feed.Messages():
+		if ok {
+			t.Fatalf("expected no message, got %+v", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the message channel to be closed")
+	}
+}
@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package cg
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the debug messages received by s.
+// It must be called before listening starts, see Messages.
+// Iteration ends when listening ends or ctx is canceled.
+func (s *DebugSocket) All(ctx context.Context) iter.Seq[DebugMessage] {
+	messages := s.Messages()
+	return func(yield func(DebugMessage) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok || !yield(message) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DebugSink receives debug messages, e.g. to forward them to a centralized log collector.
+type DebugSink interface {
+	WriteDebug(message DebugFeedMessage) error
+	Close() error
+}
+
+// ForwardTo passes every debug message received by s to sink, tagged with source.
+// Errors returned by the sink are ignored.
+func (s *DebugSocket) ForwardTo(sink DebugSink, source string) CallbackID {
+	return s.OnMessage(func(severity DebugSeverity, message, data string) {
+		sink.WriteDebug(DebugFeedMessage{Source: source, Severity: severity, Message: message, Data: data})
+	})
+}
+
+type debugRecord struct {
+	Time     time.Time       `json:"time"`
+	Source   string          `json:"source,omitempty"`
+	Severity DebugSeverity   `json:"severity"`
+	Message  string          `json:"message"`
+	Data     json.RawMessage `json:"data,omitempty"`
+}
+
+func newDebugRecord(message DebugFeedMessage) debugRecord {
+	record := debugRecord{
+		Time:     clock.Now(),
+		Source:   message.Source,
+		Severity: message.Severity,
+		Message:  message.Message,
+	}
+	if message.Data != "" && json.Valid([]byte(message.Data)) {
+		record.Data = json.RawMessage(message.Data)
+	}
+	return record
+}
+
+// NetworkSink writes debug messages as JSON lines to a TCP or UDP log collector.
+// Each message is sent in its own datagram when using UDP.
+type NetworkSink struct {
+	lock sync.Mutex
+	conn net.Conn
+}
+
+// NewNetworkSink connects to the collector at address. network is "tcp" or "udp".
+func NewNetworkSink(network, address string) (*NetworkSink, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkSink{conn: conn}, nil
+}
+
+func (n *NetworkSink) WriteDebug(message DebugFeedMessage) error {
+	data, err := json.Marshal(newDebugRecord(message))
+	if err != nil {
+		return ErrEncodeFailed
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	_, err = n.conn.Write(append(data, '\n'))
+	return err
+}
+
+func (n *NetworkSink) Close() error {
+	return n.conn.Close()
+}
+
+// HTTPSink posts debug messages in batches as JSON arrays to an HTTP endpoint.
+// A batch is sent when it is full or flushInterval has passed since the first message of the batch.
+type HTTPSink struct {
+	// Client sends the requests. The default client only sets the Content-Type header,
+	// neither the client identification nor the credentials used for the game server.
+	// It must not be changed after the first message has been written.
+	Client *http.Client
+
+	url           string
+	batchSize     int
+	flushInterval time.Duration
+
+	lock    sync.Mutex
+	batch   []debugRecord
+	timer   bool
+	closing chan struct{}
+}
+
+// NewHTTPSink creates a sink posting to url. batchSize and flushInterval default to 100 and 5s if <= 0.
+func NewHTTPSink(url string, batchSize int, flushInterval time.Duration) *HTTPSink {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if flushInterval <= 0 {
+		flushInterval = 5 * time.Second
+	}
+	return &HTTPSink{
+		url:           url,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		closing:       make(chan struct{}),
+	}
+}
+
+func (h *HTTPSink) WriteDebug(message DebugFeedMessage) error {
+	h.lock.Lock()
+	h.batch = append(h.batch, newDebugRecord(message))
+	if len(h.batch) >= h.batchSize {
+		batch := h.batch
+		h.batch = nil
+		h.lock.Unlock()
+		return h.post(batch)
+	}
+	if !h.timer {
+		h.timer = true
+		go h.flushAfterInterval()
+	}
+	h.lock.Unlock()
+	return nil
+}
+
+func (h *HTTPSink) flushAfterInterval() {
+	select {
+	case <-clock.After(h.flushInterval):
+	case <-h.closing:
+		return
+	}
+	h.Flush()
+}
+
+// Flush sends all buffered messages immediately.
+func (h *HTTPSink) Flush() error {
+	h.lock.Lock()
+	batch := h.batch
+	h.batch = nil
+	h.timer = false
+	h.lock.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	return h.post(batch)
+}
+
+func (h *HTTPSink) post(batch []debugRecord) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return ErrEncodeFailed
+	}
+	client := h.Client
+	if client == nil {
+		client = outboundClient
+	}
+	resp, err := client.Post(h.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post debug messages: %s", resp.Status)
+	}
+	return nil
+}
+
+// Close flushes the remaining messages.
+func (h *HTTPSink) Close() error {
+	close(h.closing)
+	return h.Flush()
+}
@@ -0,0 +1,74 @@
+package cg_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+type sinkRequest struct {
+	header  http.Header
+	records []map[string]any
+}
+
+func newSinkReceiver(t *testing.T) (*httptest.Server, chan sinkRequest) {
+	requests := make(chan sinkRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		var records []map[string]any
+		json.Unmarshal(data, &records)
+		requests <- sinkRequest{header: req.Header.Clone(), records: records}
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestHTTPSinkHeaders(t *testing.T) {
+	cg.SetUserAgent("custom-agent")
+	defer cg.SetUserAgent("")
+	receiver, requests := newSinkReceiver(t)
+	sink := cg.NewHTTPSink(receiver.URL, 2, 0)
+
+	sink.WriteDebug(cg.DebugFeedMessage{Source: "game", Severity: cg.DebugInfo, Message: "first"})
+	err := sink.WriteDebug(cg.DebugFeedMessage{Source: "game", Severity: cg.DebugInfo, Message: "second"})
+	if err != nil {
+		t.Fatalf("failed to post: %s", err)
+	}
+	sink.Close()
+
+	request := <-requests
+	if len(request.records) != 2 {
+		t.Errorf("expected a batch of 2 records, got %v", request.records)
+	}
+	if value := request.header.Get("Content-Type"); value != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %q", value)
+	}
+	for _, name := range []string{"Authorization", "X-Codegame-Client"} {
+		if value := request.header.Get(name); value != "" {
+			t.Errorf("expected no header %s, got %q", name, value)
+		}
+	}
+	if value := request.header.Get("User-Agent"); value == "custom-agent" {
+		t.Errorf("expected the User-Agent of the game server requests not to be sent")
+	}
+}
+
+func TestHTTPSinkClient(t *testing.T) {
+	receiver, requests := newSinkReceiver(t)
+	sink := cg.NewHTTPSink(receiver.URL, 1, 0)
+	sink.Client = &http.Client{Transport: addHeaderTransport{}}
+
+	err := sink.WriteDebug(cg.DebugFeedMessage{Source: "game", Severity: cg.DebugInfo, Message: "hello"})
+	if err != nil {
+		t.Fatalf("failed to post: %s", err)
+	}
+	sink.Close()
+
+	if value := (<-requests).header.Get("X-Custom"); value != "yes" {
+		t.Errorf("expected the request to be sent with Client, got X-Custom %q", value)
+	}
+}
@@ -2,6 +2,7 @@ package cg
 
 import (
 	"encoding/json"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -22,29 +23,51 @@ type debugMessage struct {
 	Data     json.RawMessage `json:"data,omitempty"`
 }
 
+// DebugMessage is a message received by a DebugSocket.
+type DebugMessage struct {
+	Severity DebugSeverity
+	Message  string
+	// Data is empty if no data was included in the message.
+	Data string
+}
+
 // The data argument is empty if no data was included in the message.
 type DebugMessageCallback func(severity DebugSeverity, message string, data string)
 
 type DebugSocket struct {
-	wsConn    *websocket.Conn
-	callbacks map[CallbackID]DebugMessageCallback
-	url       string
-	tls       bool
+	// connLock guards wsConn and closed, which are accessed by Close from other goroutines.
+	connLock sync.Mutex
+	wsConn   wsConnection
+	closed   bool
+
+	callbacks  map[CallbackID]DebugMessageCallback
+	url        string
+	tls        bool
+	dialConfig dialConfig
 
 	enableTrace   bool
 	enableInfo    bool
 	enableWarning bool
 	enableError   bool
 
+	messages chan DebugMessage
+
+	// done is closed when the background listener ends and err is set.
+	done     chan struct{}
+	err      error
+	stopping bool
+
 	nextCallbackID CallbackID
 }
 
-func NewDebugSocket(url string) *DebugSocket {
+func NewDebugSocket(url string, opts ...DialOption) *DebugSocket {
 	url = trimURL(url)
+	config := newDialConfig(url, opts)
 	return &DebugSocket{
 		callbacks:     make(map[CallbackID]DebugMessageCallback),
 		url:           url,
-		tls:           isTLS(url),
+		tls:           probeTLS(url, config),
+		dialConfig:    config,
 		enableTrace:   false,
 		enableInfo:    true,
 		enableWarning: true,
@@ -60,7 +83,7 @@ func (s *DebugSocket) URL() string {
 // SetSeverities panics if it is called after calling DebugServer, DebugGame or DebugPlayer.
 // When SetSeverities is never called all severities except trace are enabled.
 func (s *DebugSocket) SetSeverities(enableTrace, enableInfo, enableWarning, enableError bool) {
-	if s.wsConn != nil {
+	if s.connection() != nil {
 		panic("cannot call SetSeverities after a connection has already been established")
 	}
 	s.enableTrace = enableTrace
@@ -80,45 +103,161 @@ func (s *DebugSocket) RemoveCallback(id CallbackID) {
 	delete(s.callbacks, id)
 }
 
+// Messages returns a channel that receives all debug messages after the callbacks have been called.
+// Listening blocks while the channel is full, so slow consumers slow down the stream instead of losing messages.
+// The channel is closed when listening ends.
+// Messages panics if it is called after calling DebugServer, DebugGame, DebugPlayer or one of their Start variants.
+func (s *DebugSocket) Messages() <-chan DebugMessage {
+	if s.connection() != nil {
+		panic("cannot call Messages after a connection has already been established")
+	}
+	if s.messages == nil {
+		s.messages = make(chan DebugMessage, 10)
+	}
+	return s.messages
+}
+
 // DebugServer connects to the /api/debug endpoint on the server and listens for debug messages.
 func (s *DebugSocket) DebugServer() error {
-	wsConn, _, err := websocket.DefaultDialer.Dial(baseURL("ws", s.tls, "%s/api/debug?trace=%t&info=%t&warning=%t&error=%t", s.url, s.enableTrace, s.enableInfo, s.enableWarning, s.enableError), nil)
+	err := s.dialServer()
 	if err != nil {
 		return err
 	}
-
-	s.wsConn = wsConn
-
 	return s.listen()
 }
 
 // DebugGame connects to the /api/games/{gameId}/debug endpoint on the server and listens for debug messages.
 func (s *DebugSocket) DebugGame(gameID string) error {
-	wsConn, _, err := websocket.DefaultDialer.Dial(baseURL("ws", s.tls, "%s/api/games/%s/debug?trace=%t&info=%t&warning=%t&error=%t", s.url, gameID, s.enableTrace, s.enableInfo, s.enableWarning, s.enableError), nil)
+	err := s.dialGame(gameID)
 	if err != nil {
 		return err
 	}
-
-	s.wsConn = wsConn
-
 	return s.listen()
 }
 
 // DebugPlayer connects to the /api/games/{gameId}/players/{playerId}/debug endpoint on the server and listens for debug messages.
 func (s *DebugSocket) DebugPlayer(gameID, playerID, playerSecret string) error {
-	wsConn, _, err := websocket.DefaultDialer.Dial(baseURL("ws", s.tls, "%s/api/games/%s/players/%s/debug?player_secret=%s&trace=%t&info=%t&warning=%t&error=%t", s.url, gameID, playerID, playerSecret, s.enableTrace, s.enableInfo, s.enableWarning, s.enableError), nil)
+	err := s.dialPlayer(gameID, playerID, playerSecret)
+	if err != nil {
+		return err
+	}
+	return s.listen()
+}
+
+// StartServer is like DebugServer but listens in the background. Use Stop or Wait to end listening.
+func (s *DebugSocket) StartServer() error {
+	err := s.dialServer()
+	if err != nil {
+		return err
+	}
+	s.listenInBackground()
+	return nil
+}
+
+// StartGame is like DebugGame but listens in the background. Use Stop or Wait to end listening.
+func (s *DebugSocket) StartGame(gameID string) error {
+	err := s.dialGame(gameID)
+	if err != nil {
+		return err
+	}
+	s.listenInBackground()
+	return nil
+}
+
+// StartPlayer is like DebugPlayer but listens in the background. Use Stop or Wait to end listening.
+func (s *DebugSocket) StartPlayer(gameID, playerID, playerSecret string) error {
+	err := s.dialPlayer(gameID, playerID, playerSecret)
 	if err != nil {
 		return err
 	}
+	s.listenInBackground()
+	return nil
+}
+
+// Wait blocks until the background listener started by StartServer, StartGame or StartPlayer ends.
+// It returns nil if the connection was closed normally or with Stop.
+// Wait panics if no background listener has been started.
+func (s *DebugSocket) Wait() error {
+	if s.done == nil {
+		panic("no background listener has been started")
+	}
+	<-s.done
+	if s.err == ErrClosed || s.stopping {
+		return nil
+	}
+	return s.err
+}
 
+// Stop closes the connection and waits for the background listener to end.
+// Stop panics if no background listener has been started.
+func (s *DebugSocket) Stop() error {
+	if s.done == nil {
+		panic("no background listener has been started")
+	}
+	s.stopping = true
+	s.Close()
+	return s.Wait()
+}
+
+func (s *DebugSocket) listenInBackground() {
+	s.done = make(chan struct{})
+	go func() {
+		s.err = s.listen()
+		close(s.done)
+	}()
+}
+
+func (s *DebugSocket) dialServer() error {
+	return s.dial(baseURL("ws", s.tls, "%s/api/debug?trace=%t&info=%t&warning=%t&error=%t", s.url, s.enableTrace, s.enableInfo, s.enableWarning, s.enableError))
+}
+
+func (s *DebugSocket) dialGame(gameID string) error {
+	return s.dial(baseURL("ws", s.tls, "%s/api/games/%s/debug?trace=%t&info=%t&warning=%t&error=%t", s.url, gameID, s.enableTrace, s.enableInfo, s.enableWarning, s.enableError))
+}
+
+func (s *DebugSocket) dialPlayer(gameID, playerID, playerSecret string) error {
+	return s.dial(baseURL("ws", s.tls, "%s/api/games/%s/players/%s/debug?player_secret=%s&trace=%t&info=%t&warning=%t&error=%t", s.url, gameID, playerID, playerSecret, s.enableTrace, s.enableInfo, s.enableWarning, s.enableError))
+}
+
+// dial connects to url. It returns ErrClosed if Close is called before the connection is established.
+func (s *DebugSocket) dial(url string) error {
+	if s.isClosed() {
+		return ErrClosed
+	}
+	wsConn, err := dialWebsocket(url, s.dialConfig)
+	if err != nil {
+		return redactError(err)
+	}
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	if s.closed {
+		// Close has been called while dialing.
+		closeConnection(wsConn)
+		return ErrClosed
+	}
 	s.wsConn = wsConn
+	return nil
+}
 
-	return s.listen()
+func (s *DebugSocket) connection() wsConnection {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	return s.wsConn
+}
+
+func (s *DebugSocket) isClosed() bool {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	return s.closed
 }
 
 func (s *DebugSocket) listen() error {
+	if s.messages != nil {
+		defer close(s.messages)
+	}
+	wsConn := s.connection()
 	for {
-		msgType, msg, err := s.wsConn.ReadMessage()
+		msgType, msg, err := wsConn.ReadMessage()
 		if err != nil {
 			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived, websocket.CloseGoingAway) {
 				return ErrClosed
@@ -131,7 +270,7 @@ func (s *DebugSocket) listen() error {
 		}
 
 		var message debugMessage
-		err = json.Unmarshal(msg, &message)
+		err = codec.Unmarshal(msg, &message)
 		if err != nil {
 			return ErrDecodeFailed
 		}
@@ -140,11 +279,22 @@ func (s *DebugSocket) listen() error {
 		for _, cb := range s.callbacks {
 			cb(message.Severity, message.Message, dataStr)
 		}
+		if s.messages != nil {
+			s.messages <- DebugMessage{Severity: message.Severity, Message: message.Message, Data: dataStr}
+		}
 	}
 }
 
-// Close closes the underlying websocket connection.
+// Close closes the underlying websocket connection. It may be called from any goroutine.
+// A connection that is being established is closed as soon as the dial completes.
 func (s *DebugSocket) Close() error {
-	s.wsConn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(5*time.Second))
-	return s.wsConn.Close()
+	s.connLock.Lock()
+	s.closed = true
+	wsConn := s.wsConn
+	s.connLock.Unlock()
+	if wsConn == nil {
+		return nil
+	}
+	wsConn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(5*time.Second))
+	return wsConn.Close()
 }
@@ -0,0 +1,46 @@
+//go:build !windows && !plan9 && !js
+
+package cg
+
+import (
+	"log/syslog"
+)
+
+// SyslogSink forwards debug messages to syslog. The severity is mapped to the syslog priority
+// and the source is prepended to the message.
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink connects to the syslog daemon at raddr using network ("udp", "tcp" or "" for the local daemon).
+func NewSyslogSink(network, raddr, tag string) (*SyslogSink, error) {
+	writer, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+func (s *SyslogSink) WriteDebug(message DebugFeedMessage) error {
+	text := message.Message
+	if message.Source != "" {
+		text = "[" + message.Source + "] " + text
+	}
+	if message.Data != "" {
+		text += " " + message.Data
+	}
+	switch message.Severity {
+	case DebugError:
+		return s.writer.Err(text)
+	case DebugWarning:
+		return s.writer.Warning(text)
+	case DebugTrace:
+		return s.writer.Debug(text)
+	default:
+		return s.writer.Info(text)
+	}
+}
+
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
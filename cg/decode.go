@@ -0,0 +1,71 @@
+package cg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// decodeStrict decodes data into targetObjPtr, rejecting unknown fields and missing fields tagged with `cg:"required"`.
+func decodeStrict(data []byte, targetObjPtr any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(targetObjPtr)
+	if err != nil {
+		return err
+	}
+	return checkRequiredFields(data, targetObjPtr)
+}
+
+// checkRequiredFields verifies that every struct field tagged with `cg:"required"` is present in data.
+// Fields of embedded structs are checked as well because encoding/json promotes them.
+func checkRequiredFields(data []byte, targetObjPtr any) error {
+	t := derefType(reflect.TypeOf(targetObjPtr))
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	return checkRequiredStructFields(t, fields)
+}
+
+func checkRequiredStructFields(t reflect.Type, fields map[string]json.RawMessage) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if embedded := derefType(field.Type); field.Anonymous && tag == "" && embedded.Kind() == reflect.Struct {
+			err := checkRequiredStructFields(embedded, fields)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("cg") != "required" {
+			continue
+		}
+		name := field.Name
+		if tag != "" {
+			name = tag
+		}
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("missing required field '%s'", name)
+		}
+	}
+	return nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,71 @@
+package cg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type RequiredBase struct {
+	ID string `json:"id" cg:"required"`
+}
+
+type RequiredNested struct {
+	Inner string `json:"inner" cg:"required"`
+}
+
+type requiredEmbedded struct {
+	RequiredBase
+	*RequiredNested
+	Name string `json:"name" cg:"required"`
+}
+
+type requiredTaggedEmbedded struct {
+	RequiredBase `json:"base" cg:"required"`
+}
+
+type dropped struct {
+	X int `json:"x"`
+}
+
+type droppedRequired struct {
+	X int `json:"x"`
+	Y int `json:"y" cg:"required"`
+}
+
+func TestStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  Event
+		target any
+		valid  bool
+	}{
+		{name: "all required fields", event: Event{Data: json.RawMessage(`{"id":"1","inner":"a","name":"b"}`)}, target: &requiredEmbedded{}, valid: true},
+		{name: "missing field", event: Event{Data: json.RawMessage(`{"id":"1","inner":"a"}`)}, target: &requiredEmbedded{}},
+		{name: "missing field of embedded struct", event: Event{Data: json.RawMessage(`{"inner":"a","name":"b"}`)}, target: &requiredEmbedded{}},
+		{name: "missing field of embedded pointer", event: Event{Data: json.RawMessage(`{"id":"1","name":"b"}`)}, target: &requiredEmbedded{}},
+		{name: "tagged embedded struct", event: Event{Data: json.RawMessage(`{"base":{"id":"1"}}`)}, target: &requiredTaggedEmbedded{}, valid: true},
+		{name: "missing tagged embedded struct", event: Event{Data: json.RawMessage(`{}`)}, target: &requiredTaggedEmbedded{}},
+		{name: "unknown field", event: Event{Data: json.RawMessage(`{"id":"1","inner":"a","name":"b","extra":1}`)}, target: &requiredEmbedded{}},
+		{name: "dropped data", event: Event{decoded: dropped{X: 1}}, target: &dropped{}, valid: true},
+		{name: "unknown field of dropped data", event: Event{decoded: dropped{X: 1}}, target: &struct{}{}},
+		{name: "missing field of dropped data", event: Event{decoded: dropped{X: 1}}, target: &droppedRequired{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.event.strict = true
+			err := test.event.UnmarshalData(test.target)
+			if test.valid && err != nil {
+				t.Errorf("expected the data to be accepted, got %s", err)
+			}
+			if !test.valid && err == nil {
+				t.Error("expected the data to be rejected")
+			}
+
+			test.event.strict = false
+			err = test.event.UnmarshalData(test.target)
+			if err != nil {
+				t.Errorf("expected the data to be accepted without strict decoding, got %s", err)
+			}
+		})
+	}
+}
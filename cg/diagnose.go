@@ -0,0 +1,170 @@
+package cg
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// DiagnosticCheck is the result of a single step of Diagnose.
+type DiagnosticCheck struct {
+	Name     string
+	OK       bool
+	Skipped  bool
+	Duration time.Duration
+	// Detail contains additional information, e.g. resolved addresses or the server version.
+	Detail string
+	Err    error
+}
+
+// DiagnosticReport is the result of Diagnose.
+type DiagnosticReport struct {
+	GameURL string
+	TLS     bool
+	Checks  []DiagnosticCheck
+}
+
+// OK reports whether all checks that were not skipped passed.
+func (r DiagnosticReport) OK() bool {
+	for _, check := range r.Checks {
+		if !check.Skipped && !check.OK {
+			return false
+		}
+	}
+	return true
+}
+
+func (r DiagnosticReport) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Diagnostics for %s:\n", r.GameURL)
+	for _, check := range r.Checks {
+		status := "OK"
+		if check.Skipped {
+			status = "SKIPPED"
+		} else if !check.OK {
+			status = "FAILED"
+		}
+		fmt.Fprintf(&builder, "  %-10s %-8s %s", check.Name, status, check.Duration.Round(time.Millisecond))
+		if check.Detail != "" {
+			fmt.Fprintf(&builder, " (%s)", check.Detail)
+		}
+		if check.Err != nil {
+			fmt.Fprintf(&builder, ": %s", check.Err)
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// Diagnose checks DNS resolution, TCP reachability, TLS validity, the /api/info endpoint and a websocket upgrade
+// for the server at gameURL. Checks depending on a failed check are skipped.
+func Diagnose(gameURL string, opts ...DialOption) DiagnosticReport {
+	gameURL = trimURL(gameURL)
+	config := newDialConfig(gameURL, opts)
+	report := DiagnosticReport{GameURL: gameURL}
+
+	url, err := neturl.Parse("http://" + gameURL)
+	if err != nil {
+		report.Checks = append(report.Checks, DiagnosticCheck{Name: "url", Err: err})
+		return report
+	}
+	host := url.Hostname()
+
+	failed := false
+	run := func(name string, fn func() (string, error)) {
+		check := DiagnosticCheck{Name: name}
+		if failed {
+			check.Skipped = true
+			report.Checks = append(report.Checks, check)
+			return
+		}
+		start := time.Now()
+		check.Detail, check.Err = fn()
+		check.Duration = time.Since(start)
+		check.OK = check.Err == nil
+		failed = !check.OK
+		report.Checks = append(report.Checks, check)
+	}
+
+	network, address := "tcp", ""
+	if socketPath, ok := unixSocketPath(host); ok {
+		network, address = "unix", socketPath
+		report.Checks = append(report.Checks, DiagnosticCheck{Name: "dns", Skipped: true, Detail: "unix domain socket"})
+	} else {
+		run("dns", func() (string, error) {
+			addrs, err := net.LookupHost(host)
+			return strings.Join(addrs, ", "), err
+		})
+	}
+
+	report.TLS = probeTLS(gameURL, config)
+	port := url.Port()
+	if port == "" {
+		port = "80"
+		if report.TLS {
+			port = "443"
+		}
+	}
+	if address == "" {
+		address = net.JoinHostPort(host, port)
+	}
+
+	run(network, func() (string, error) {
+		conn, err := net.DialTimeout(network, address, config.dialTimeout)
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+		return conn.RemoteAddr().String(), nil
+	})
+
+	if report.TLS {
+		run("tls", func() (string, error) {
+			conn, err := tls.DialWithDialer(&net.Dialer{Timeout: config.dialTimeout + config.tlsHandshakeTimeout}, "tcp", net.JoinHostPort(host, port), &tls.Config{ServerName: host})
+			if err != nil {
+				return "", err
+			}
+			defer conn.Close()
+			expiry := conn.ConnectionState().PeerCertificates[0].NotAfter
+			return "valid until " + expiry.Format(time.RFC3339), nil
+		})
+	} else {
+		report.Checks = append(report.Checks, DiagnosticCheck{Name: "tls", Skipped: true, Detail: "server does not support TLS"})
+	}
+
+	run("api/info", func() (string, error) {
+		info, err := fetchInfo(config.httpClient(), gameURL, report.TLS)
+		if err != nil {
+			return "", err
+		}
+		if !compatibleCGVersion(info.CGVersion) {
+			return fmt.Sprintf("%s %s, CodeGame v%s", info.Name, info.Version, info.CGVersion), fmt.Errorf("incompatible CodeGame version: client: v%s, server: v%s", CGVersion, info.CGVersion)
+		}
+		return fmt.Sprintf("%s %s, CodeGame v%s", info.Name, info.Version, info.CGVersion), nil
+	})
+
+	run("websocket", func() (string, error) {
+		conn, err := dialWebsocket(baseURL("ws", report.TLS, "%s/api/debug", gameURL), config)
+		if err != nil {
+			return "", err
+		}
+		return "", conn.Close()
+	})
+
+	return report
+}
+
+// compatibleCGVersion reports whether the server version has the same major and minor version as CGVersion.
+func compatibleCGVersion(serverVersion string) bool {
+	trim := func(version string) string {
+		parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+		if len(parts) > 2 {
+			parts = parts[:2]
+		}
+		return strings.Join(parts, ".")
+	}
+	return trim(serverVersion) == trim(CGVersion)
+}
@@ -0,0 +1,53 @@
+//go:build !js && !cg_coderws
+
+package cg
+
+import (
+	"net"
+	"net/http"
+	neturl "net/url"
+
+	"github.com/gorilla/websocket"
+)
+
+func dialWebsocket(url string, config dialConfig) (wsConnection, error) {
+	netDialer := &net.Dialer{Timeout: config.dialTimeout}
+	dialer := &websocket.Dialer{
+		Proxy:            proxyFromEnvironment,
+		NetDialContext:   unixAwareDialer(netDialer.DialContext),
+		HandshakeTimeout: config.handshakeTimeout,
+	}
+	if usesProxy(url) {
+		// NetDialTLSContext would be used to connect to the proxy itself,
+		// so the TLS handshake is only limited by the handshake timeout.
+		dialer.TLSClientConfig = config.tlsConfig("")
+	} else {
+		dialer.NetDialTLSContext = config.tlsDialer(netDialer)
+	}
+	header := requestHeaders()
+	err := addAuthorization(header, config.tokenSource)
+	if err != nil {
+		return nil, &ConnectError{Stage: StageAuth, Err: err}
+	}
+	wsConn, resp, err := dialer.Dial(url, header)
+	if err != nil {
+		return nil, handshakeError(resp, err)
+	}
+	return wsConn, nil
+}
+
+// usesProxy reports whether the connection to the websocket url is established through a proxy from the environment.
+func usesProxy(url string) bool {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+	proxy, err := proxyFromEnvironment(&http.Request{URL: u})
+	return err != nil || proxy != nil
+}
@@ -0,0 +1,42 @@
+//go:build cg_coderws && !js
+
+package cg
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	coderws "github.com/coder/websocket"
+)
+
+func dialWebsocket(url string, config dialConfig) (wsConnection, error) {
+	netDialer := &net.Dialer{Timeout: config.dialTimeout}
+	transport := newHTTPTransport()
+	transport.DialContext = unixAwareDialer(netDialer.DialContext)
+	// A custom TLS dialer would bypass proxies, so TLS is configured on the transport.
+	transport.TLSClientConfig = config.tlsConfig("")
+	transport.TLSHandshakeTimeout = config.tlsHandshakeTimeout
+	transport.ForceAttemptHTTP2 = false
+
+	header := requestHeaders()
+	err := addAuthorization(header, config.tokenSource)
+	if err != nil {
+		return nil, &ConnectError{Stage: StageAuth, Err: err}
+	}
+
+	ctx := context.Background()
+	if config.handshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.handshakeTimeout)
+		defer cancel()
+	}
+	conn, resp, err := coderws.Dial(ctx, url, &coderws.DialOptions{
+		HTTPClient: &http.Client{Transport: transport},
+		HTTPHeader: header,
+	})
+	if err != nil {
+		return nil, handshakeError(resp, err)
+	}
+	return newCoderConn(conn), nil
+}
@@ -0,0 +1,29 @@
+//go:build cg_coderws && js && wasm
+
+package cg
+
+import (
+	"context"
+
+	coderws "github.com/coder/websocket"
+)
+
+// dialWebsocket connects using the browser. Only the handshake timeout of config is supported.
+func dialWebsocket(url string, config dialConfig) (wsConnection, error) {
+	url, err := addAccessToken(url, config.tokenSource)
+	if err != nil {
+		return nil, &ConnectError{Stage: StageAuth, Err: err}
+	}
+
+	ctx := context.Background()
+	if config.handshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.handshakeTimeout)
+		defer cancel()
+	}
+	conn, _, err := coderws.Dial(ctx, url, nil)
+	if err != nil {
+		return nil, &ConnectError{Stage: StageWebsocket, Err: redactError(err)}
+	}
+	return newCoderConn(conn), nil
+}
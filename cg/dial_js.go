@@ -0,0 +1,200 @@
+//go:build js && wasm && !cg_coderws
+
+package cg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type jsMessage struct {
+	messageType int
+	data        []byte
+}
+
+// jsConn implements wsConnection using the WebSocket API of the browser.
+type jsConn struct {
+	ws    js.Value
+	funcs []js.Func
+
+	lock          sync.Mutex
+	cond          *sync.Cond
+	queue         []jsMessage
+	closeErr      error
+	deadline      time.Time
+	deadlineTimer *time.Timer
+}
+
+// dialWebsocket connects using the browser. Only the handshake timeout of config is supported.
+func dialWebsocket(url string, config dialConfig) (wsConnection, error) {
+	url, err := addAccessToken(url, config.tokenSource)
+	if err != nil {
+		return nil, &ConnectError{Stage: StageAuth, Err: err}
+	}
+
+	conn := &jsConn{
+		ws: js.Global().Get("WebSocket").New(url),
+	}
+	conn.cond = sync.NewCond(&conn.lock)
+	conn.ws.Set("binaryType", "arraybuffer")
+
+	opened := make(chan error, 1)
+	conn.on("open", func(js.Value) {
+		select {
+		case opened <- nil:
+		default:
+		}
+	})
+	conn.on("error", func(js.Value) {
+		select {
+		case opened <- errors.New("failed to open websocket connection"):
+		default:
+		}
+	})
+	conn.on("message", func(event js.Value) {
+		data := event.Get("data")
+		msg := jsMessage{messageType: websocket.TextMessage}
+		if data.Type() == js.TypeString {
+			msg.data = []byte(data.String())
+		} else {
+			array := js.Global().Get("Uint8Array").New(data)
+			msg.messageType = websocket.BinaryMessage
+			msg.data = make([]byte, array.Get("length").Int())
+			js.CopyBytesToGo(msg.data, array)
+		}
+		conn.lock.Lock()
+		conn.queue = append(conn.queue, msg)
+		conn.lock.Unlock()
+		conn.cond.Signal()
+	})
+	conn.on("close", func(event js.Value) {
+		conn.lock.Lock()
+		conn.closeErr = &websocket.CloseError{Code: event.Get("code").Int(), Text: event.Get("reason").String()}
+		conn.lock.Unlock()
+		conn.cond.Broadcast()
+		select {
+		case opened <- conn.closeErr:
+		default:
+		}
+	})
+
+	var timeout <-chan time.Time
+	if config.handshakeTimeout > 0 {
+		timeout = time.After(config.handshakeTimeout)
+	}
+	select {
+	case err = <-opened:
+	case <-timeout:
+		conn.ws.Call("close")
+		err = errors.New("websocket handshake timed out")
+	}
+	if err != nil {
+		conn.release()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c *jsConn) on(event string, callback func(event js.Value)) {
+	fn := js.FuncOf(func(this js.Value, args []js.Value) any {
+		callback(args[0])
+		return nil
+	})
+	c.funcs = append(c.funcs, fn)
+	c.ws.Call("addEventListener", event, fn)
+}
+
+func (c *jsConn) release() {
+	for _, fn := range c.funcs {
+		fn.Release()
+	}
+	c.funcs = nil
+}
+
+func (c *jsConn) ReadMessage() (int, []byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.queue) == 0 && c.closeErr == nil && !c.deadlineExceeded() {
+		c.cond.Wait()
+	}
+	if len(c.queue) == 0 {
+		if c.closeErr != nil {
+			return 0, nil, c.closeErr
+		}
+		return 0, nil, os.ErrDeadlineExceeded
+	}
+	msg := c.queue[0]
+	c.queue = c.queue[1:]
+	return msg.messageType, msg.data, nil
+}
+
+func (c *jsConn) NextReader() (int, io.Reader, error) {
+	messageType, data, err := c.ReadMessage()
+	if err != nil {
+		return 0, nil, err
+	}
+	return messageType, bytes.NewReader(data), nil
+}
+
+func (c *jsConn) deadlineExceeded() bool {
+	return !c.deadline.IsZero() && !time.Now().Before(c.deadline)
+}
+
+func (c *jsConn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.deadline = t
+	if c.deadlineTimer != nil {
+		c.deadlineTimer.Stop()
+		c.deadlineTimer = nil
+	}
+	if !t.IsZero() {
+		c.deadlineTimer = time.AfterFunc(time.Until(t), c.cond.Broadcast)
+	}
+	return nil
+}
+
+// SetWriteDeadline is a no-op because the browser buffers outgoing messages without blocking.
+func (c *jsConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// SetPongHandler is a no-op because the browser does not expose pongs.
+func (c *jsConn) SetPongHandler(h func(appData string) error) {}
+
+func (c *jsConn) WriteMessage(messageType int, data []byte) error {
+	if messageType == websocket.TextMessage {
+		c.ws.Call("send", string(data))
+		return nil
+	}
+	array := js.Global().Get("Uint8Array").New(len(data))
+	js.CopyBytesToJS(array, data)
+	c.ws.Call("send", array)
+	return nil
+}
+
+// WriteControl only supports close messages because the browser handles pings and pongs itself.
+func (c *jsConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	if messageType != websocket.CloseMessage {
+		return nil
+	}
+	code := websocket.CloseNormalClosure
+	if len(data) >= 2 {
+		code = int(data[0])<<8 | int(data[1])
+	}
+	c.ws.Call("close", code)
+	return nil
+}
+
+func (c *jsConn) Close() error {
+	c.ws.Call("close")
+	c.release()
+	return nil
+}
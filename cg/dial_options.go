@@ -0,0 +1,75 @@
+package cg
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DialOption configures how connections to a server are established.
+type DialOption func(config *dialConfig)
+
+type dialConfig struct {
+	dialTimeout         time.Duration
+	tlsHandshakeTimeout time.Duration
+	handshakeTimeout    time.Duration
+
+	// pinnedKeys contains base64-encoded SHA-256 hashes of accepted public keys.
+	pinnedKeys []string
+	// clientCertificates are presented to servers requesting mutual TLS.
+	clientCertificates []tls.Certificate
+	// rootCAs replaces the system roots if set. It is only set by tests.
+	rootCAs *x509.CertPool
+
+	// tokenSource supplies the bearer token for the game server. See WithTokenSource.
+	tokenSource TokenSource
+
+	// client is used for REST requests if the TLS configuration is customized or a token source is set. See httpClient.
+	client *http.Client
+}
+
+// newDialConfig applies opts for connections to the server at trimmedURL.
+func newDialConfig(trimmedURL string, opts []DialOption) dialConfig {
+	config := dialConfig{
+		dialTimeout:         30 * time.Second,
+		tlsHandshakeTimeout: 10 * time.Second,
+		handshakeTimeout:    45 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.customTLS() || config.tokenSource != nil {
+		transport := newHTTPTransport()
+		transport.TLSClientConfig = config.tlsConfig("")
+		config.client = &http.Client{Transport: headerTransport{
+			base:        transport,
+			tokenSource: config.tokenSource,
+			host:        strings.SplitN(trimmedURL, "/", 2)[0],
+		}}
+	}
+	return config
+}
+
+// WithDialTimeout limits the time for establishing the TCP connection. Default: 30s.
+func WithDialTimeout(timeout time.Duration) DialOption {
+	return func(config *dialConfig) {
+		config.dialTimeout = timeout
+	}
+}
+
+// WithTLSHandshakeTimeout limits the time for the TLS handshake. Default: 10s.
+// Websocket connections through a proxy are only limited by the handshake timeout.
+func WithTLSHandshakeTimeout(timeout time.Duration) DialOption {
+	return func(config *dialConfig) {
+		config.tlsHandshakeTimeout = timeout
+	}
+}
+
+// WithHandshakeTimeout limits the time for the complete websocket handshake including dialing and TLS. Default: 45s.
+func WithHandshakeTimeout(timeout time.Duration) DialOption {
+	return func(config *dialConfig) {
+		config.handshakeTimeout = timeout
+	}
+}
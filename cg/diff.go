@@ -0,0 +1,117 @@
+package cg
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind describes how a value differs between two snapshots.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a single difference between two snapshots.
+type Change struct {
+	Kind ChangeKind
+	// Path is the JSON Pointer (RFC 6901) of the changed value, e.g. "/players/0/score".
+	Path string
+	// Old is nil for added values and New is nil for removed values.
+	Old any
+	New any
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %v", c.Path, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %v", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Path, c.Old, c.New)
+	}
+}
+
+// Diff compares the JSON representations of a and b, e.g. two snapshots of a game-state struct,
+// and returns the changed paths sorted by path.
+func Diff(a, b any) ([]Change, error) {
+	dataA, err := json.Marshal(a)
+	if err != nil {
+		return nil, ErrEncodeFailed
+	}
+	dataB, err := json.Marshal(b)
+	if err != nil {
+		return nil, ErrEncodeFailed
+	}
+	return DiffJSON(dataA, dataB)
+}
+
+// DiffJSON compares two JSON documents and returns the changed paths sorted by path.
+// Arrays are compared element by element.
+func DiffJSON(a, b json.RawMessage) ([]Change, error) {
+	valueA, err := decodeJSONValue(a)
+	if err != nil {
+		return nil, err
+	}
+	valueB, err := decodeJSONValue(b)
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	diffValues("", valueA, valueB, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+func diffValues(path string, a, b any, changes *[]Change) {
+	switch a := a.(type) {
+	case map[string]any:
+		if b, ok := b.(map[string]any); ok {
+			for key, valueA := range a {
+				valuePath := path + "/" + escapePointerToken(key)
+				if valueB, ok := b[key]; ok {
+					diffValues(valuePath, valueA, valueB, changes)
+				} else {
+					*changes = append(*changes, Change{Kind: ChangeRemoved, Path: valuePath, Old: valueA})
+				}
+			}
+			for key, valueB := range b {
+				if _, ok := a[key]; !ok {
+					*changes = append(*changes, Change{Kind: ChangeAdded, Path: path + "/" + escapePointerToken(key), New: valueB})
+				}
+			}
+			return
+		}
+	case []any:
+		if b, ok := b.([]any); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				valuePath := path + "/" + strconv.Itoa(i)
+				switch {
+				case i >= len(b):
+					*changes = append(*changes, Change{Kind: ChangeRemoved, Path: valuePath, Old: a[i]})
+				case i >= len(a):
+					*changes = append(*changes, Change{Kind: ChangeAdded, Path: valuePath, New: b[i]})
+				default:
+					diffValues(valuePath, a[i], b[i], changes)
+				}
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Kind: ChangeModified, Path: path, Old: a, New: b})
+	}
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
@@ -1,5 +1,19 @@
 /*
 Package cg implements common client logic for connecting with a CodeGame server and handling events.
+
+Socket is the single entry point of the package. Players connect with Connect and Dial offers the full set of options.
+Without WithPlayer or WithCredentials, Dial connects as a spectator:
+
+	socket, err := cg.Dial(gameURL, cg.WithGame(gameID))
+	if err != nil {
+		log.Fatalf("error: %s", err)
+	}
+	defer socket.Close()
+
+	socket.OnAny(func(event cg.Event) {
+		fmt.Println(cg.FormatEvent(event, false))
+	})
+	err = socket.RunEventLoop()
 */
 package cg
 
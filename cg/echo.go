@@ -0,0 +1,21 @@
+package cg
+
+// SetEchoSuppression enables/disables the suppression of echoed events.
+// An event is an echo if the server attributes it to the player of the socket (see Event.Echo).
+// Suppressed events are still recorded in the journal but not dispatched to listeners.
+// Standard events like NewPlayerEvent are never suppressed.
+// Suppression only works with servers that provide origin metadata.
+func (s *Socket) SetEchoSuppression(enable bool) {
+	s.suppressEchoes = enable
+}
+
+// WithEchoSuppression is equivalent to calling SetEchoSuppression after connecting.
+func WithEchoSuppression(enable bool) Option {
+	return func(config *socketConfig) {
+		config.suppressEchoes = enable
+	}
+}
+
+func (s *Socket) isSuppressedEcho(event Event) bool {
+	return s.suppressEchoes && event.Echo && !IsStandardEvent(event.Name)
+}
@@ -0,0 +1,71 @@
+package cg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Environment variables read by ConnectFromEnv.
+const (
+	EnvGameURL      = "CG_GAME_URL"
+	EnvGameID       = "CG_GAME_ID"
+	EnvPlayerID     = "CG_PLAYER_ID"
+	EnvPlayerSecret = "CG_PLAYER_SECRET"
+	EnvUsername     = "CG_USERNAME"
+	EnvJoinSecret   = "CG_JOIN_SECRET"
+	// EnvConfig overrides the path of the config file. See ConfigPath.
+	EnvConfig = "CG_CONFIG"
+)
+
+// ConnectFromEnv connects to a game configured with environment variables.
+//
+// The game URL is read from CG_GAME_URL and falls back to the game_url of the .codegame.json file of the project
+// and the default game server of the config file.
+// CG_GAME_ID is required.
+// If CG_PLAYER_ID and CG_PLAYER_SECRET are set, the socket connects to the existing player.
+// Otherwise a new player named CG_USERNAME, or the preferred username of the config file, is created
+// using the optional CG_JOIN_SECRET.
+func ConnectFromEnv() (*Socket, error) {
+	gameURL := os.Getenv(EnvGameURL)
+	if gameURL == "" {
+		info, err := FindProjectInfo()
+		if err == nil {
+			gameURL = info.GameURL
+		}
+		if gameURL == "" {
+			gameURL = Config().GameURL
+		}
+		if gameURL == "" {
+			if err == nil {
+				err = errors.New("no game URL in .codegame.json")
+			}
+			return nil, fmt.Errorf("%s is not set and the game URL could not be determined from .codegame.json: %w", EnvGameURL, err)
+		}
+	}
+
+	gameID := os.Getenv(EnvGameID)
+	if gameID == "" {
+		return nil, fmt.Errorf("%s is not set", EnvGameID)
+	}
+
+	playerID := os.Getenv(EnvPlayerID)
+	playerSecret := os.Getenv(EnvPlayerSecret)
+	if playerID != "" && playerSecret != "" {
+		return Connect(gameURL, gameID, playerID, playerSecret)
+	}
+
+	username := os.Getenv(EnvUsername)
+	if username == "" {
+		username = Config().Username
+	}
+	if username == "" {
+		return nil, errors.New("either " + EnvPlayerID + " and " + EnvPlayerSecret + " or " + EnvUsername + " must be set")
+	}
+
+	playerID, playerSecret, err := JoinGame(gameURL, gameID, username, os.Getenv(EnvJoinSecret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to join game: %w", err)
+	}
+	return Connect(gameURL, gameID, playerID, playerSecret)
+}
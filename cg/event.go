@@ -2,6 +2,7 @@ package cg
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type (
@@ -11,9 +12,49 @@ type (
 
 type EventName string
 
+// anyEvent is the key of listeners registered with OnAny.
+// Received events with an empty name are rejected, so it cannot collide with a real event.
+const anyEvent EventName = ""
+
+const (
+	// SequenceGapEvent is generated locally when the sequence numbers of received events skip values.
+	// Its data is a SequenceGap.
+	SequenceGapEvent EventName = "cg_sequence_gap"
+)
+
 type Event struct {
 	Name EventName       `json:"name"`
 	Data json.RawMessage `json:"data"`
+	// Sequence is the sequence number assigned by the server or 0 if the server does not number its events.
+	Sequence uint64 `json:"seq,omitempty"`
+	// Origin is the ID of the player who caused the event or OriginServer if the server emitted it on its own.
+	// It is empty if the server does not provide it.
+	Origin string `json:"origin,omitempty"`
+	// Target is the ID of the player the event was sent to. It is empty for events sent to all players
+	// or if the server does not provide it.
+	Target string `json:"target,omitempty"`
+	// Echo is true if Origin is the player of the receiving socket, i.e. the event was caused by one of its own commands.
+	Echo bool `json:"-"`
+	// Epoch is the number of the connection the event was received on (see Socket.Epoch) or 0 for events generated locally.
+	Epoch int `json:"-"`
+	// ReceivedAt is the time the event was received or the zero time for events generated locally.
+	ReceivedAt time.Time `json:"-"`
+	// ClientSequence numbers the received events of a socket in the order they were received, starting at 1.
+	// Unlike Sequence it is always set and continues across reconnects. It is 0 for events generated locally.
+	ClientSequence uint64 `json:"-"`
+
+	strict  bool
+	decoded any
+	// gameID is the game of an event received over a multiplexed connection. See SpectateMultiplexed.
+	gameID string
+}
+
+// OriginServer is the origin of events that were not caused by a player.
+const OriginServer = "server"
+
+// FromServer reports whether the server emitted the event on its own.
+func (e Event) FromServer() bool {
+	return e.Origin == OriginServer
 }
 
 type CommandName string
@@ -24,13 +65,31 @@ type Command struct {
 }
 
 // UnmarshalData decodes the event data into the struct pointed to by targetObjPtr.
+// If the event was received by a socket with strict decoding enabled, unknown fields
+// and missing fields tagged with `cg:"required"` result in an error.
 func (e *Event) UnmarshalData(targetObjPtr any) error {
-	return json.Unmarshal(e.Data, targetObjPtr)
+	data, err := e.rawData()
+	if err != nil {
+		return err
+	}
+	if e.strict {
+		return decodeStrict(data, targetObjPtr)
+	}
+	return codec.Unmarshal(data, targetObjPtr)
+}
+
+// rawData returns the data of the event. If the raw data has been dropped (see Socket.SetDropRawData),
+// the decoded value is encoded again.
+func (e Event) rawData() (json.RawMessage, error) {
+	if e.Data == nil && e.decoded != nil {
+		return codec.Marshal(e.decoded)
+	}
+	return e.Data, nil
 }
 
 // marshalData encodes obj into the Data field of the command.
 func (c *Command) marshalData(obj any) error {
-	data, err := json.Marshal(obj)
+	data, err := codec.Marshal(obj)
 	if err != nil {
 		return err
 	}
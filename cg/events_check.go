@@ -0,0 +1,17 @@
+package cg
+
+// SetEventsDefinition makes On and Once log a warning through the logger of the socket
+// when a listener is registered for an event that def does not declare.
+// Passing nil disables the check.
+func (s *Socket) SetEventsDefinition(def *EventsDefinition) {
+	s.eventsDefinition = def
+}
+
+func (s *Socket) checkEventName(event EventName) {
+	if s.eventsDefinition == nil || event == anyEvent || IsStandardEvent(event) {
+		return
+	}
+	if _, ok := s.eventsDefinition.Event(event); !ok {
+		s.logf("cg: registered listener for event '%s', which is not defined by game '%s'", event, s.eventsDefinition.Name)
+	}
+}
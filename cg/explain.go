@@ -0,0 +1,183 @@
+package cg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUndefinedEvent     = errors.New("event is not defined")
+	ErrNoEventsDefinition = errors.New("no events definition set")
+)
+
+// Explain returns a description of event in which every field of the event data
+// is annotated with its type and the documentation from the definition.
+// Nested types, lists and maps are explained recursively.
+func (d *EventsDefinition) Explain(event Event) (string, error) {
+	obj, ok := d.Event(event.Name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUndefinedEvent, event.Name)
+	}
+
+	data, err := event.rawData()
+	if err != nil {
+		return "", err
+	}
+	var value any
+	if len(bytes.TrimSpace(data)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err := decoder.Decode(&value)
+		if err != nil {
+			return "", fmt.Errorf("invalid event data: %w", err)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("event " + obj.Name + "\n")
+	writeDoc(&b, "", obj.Doc)
+	fields, _ := value.(map[string]any)
+	d.explainFields(&b, "  ", obj, fields)
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// Explain explains event using the definition set with SetEventsDefinition or WithEventsCheck.
+// See EventsDefinition.Explain.
+func (s *Socket) Explain(event Event) (string, error) {
+	if s.eventsDefinition == nil {
+		return "", ErrNoEventsDefinition
+	}
+	return s.eventsDefinition.Explain(event)
+}
+
+func (d *EventsDefinition) explainFields(b *strings.Builder, indent string, obj ObjectDefinition, fields map[string]any) {
+	defined := make(map[string]struct{}, len(obj.Properties))
+	for _, prop := range obj.Properties {
+		defined[prop.Name] = struct{}{}
+		value, present := fields[prop.Name]
+		d.explainValue(b, indent, prop.Name, prop.Type, prop.Doc, value, present)
+	}
+
+	var undefined []string
+	for name := range fields {
+		if _, ok := defined[name]; !ok {
+			undefined = append(undefined, name)
+		}
+	}
+	sort.Strings(undefined)
+	for _, name := range undefined {
+		fmt.Fprintf(b, "%s%s: (not defined) = %s\n", indent, name, compactJSON(fields[name]))
+	}
+}
+
+func (d *EventsDefinition) explainValue(b *strings.Builder, indent, name, typ, doc string, value any, present bool) {
+	b.WriteString(indent + name + ": " + typ)
+	switch {
+	case !present:
+		b.WriteString(" (missing)")
+	case !isContainer(value):
+		b.WriteString(" = " + compactJSON(value))
+	}
+	b.WriteString("\n")
+
+	if enum, ok := d.enum(typ); ok {
+		doc = joinDoc(doc, enum.Doc)
+		if s, ok := value.(string); ok {
+			for _, v := range enum.Values {
+				if v.Name == s && v.Doc != "" {
+					doc = joinDoc(doc, v.Name+": "+v.Doc)
+				}
+			}
+		}
+	}
+	writeDoc(b, indent+"  ", doc)
+
+	switch v := value.(type) {
+	case map[string]any:
+		if inner, ok := cutContainer(typ, "map"); ok {
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				d.explainValue(b, indent+"  ", "["+key+"]", inner, "", v[key], true)
+			}
+		} else if obj, ok := d.typ(typ); ok {
+			writeDoc(b, indent+"  ", obj.Doc)
+			d.explainFields(b, indent+"  ", obj, v)
+		} else {
+			fmt.Fprintf(b, "%s  = %s\n", indent, compactJSON(v))
+		}
+	case []any:
+		inner, ok := cutContainer(typ, "list")
+		if !ok {
+			fmt.Fprintf(b, "%s  = %s\n", indent, compactJSON(v))
+			return
+		}
+		for i, element := range v {
+			d.explainValue(b, indent+"  ", fmt.Sprintf("[%d]", i), inner, "", element, true)
+		}
+	}
+}
+
+func (d *EventsDefinition) typ(name string) (ObjectDefinition, bool) {
+	return findObject(d.Types, name)
+}
+
+func (d *EventsDefinition) enum(name string) (EnumDefinition, bool) {
+	for _, e := range d.Enums {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return EnumDefinition{}, false
+}
+
+// cutContainer returns T if typ is kind<T>.
+func cutContainer(typ, kind string) (string, bool) {
+	if !strings.HasPrefix(typ, kind+"<") || !strings.HasSuffix(typ, ">") {
+		return "", false
+	}
+	return typ[len(kind)+1 : len(typ)-1], true
+}
+
+func isContainer(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any:
+		return true
+	default:
+		return false
+	}
+}
+
+func compactJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func joinDoc(doc, more string) string {
+	if doc == "" {
+		return more
+	}
+	if more == "" {
+		return doc
+	}
+	return doc + "\n" + more
+}
+
+func writeDoc(b *strings.Builder, indent, doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(doc, "\n") {
+		b.WriteString(indent + "// " + line + "\n")
+	}
+}
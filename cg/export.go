@@ -0,0 +1,179 @@
+package cg
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ExportColumn selects a field of the event data.
+// Path uses dot notation with optional array indices, e.g. `player.position.x` or `players[0].name`.
+// An empty path selects the whole event data.
+type ExportColumn struct {
+	Name string
+	Path string
+}
+
+// EventExporter flattens events into CSV or JSONL rows.
+// Every row starts with the receive time and the event name followed by the configured columns.
+// Missing fields result in empty CSV cells or null JSON values.
+type EventExporter struct {
+	lock    sync.Mutex
+	columns []ExportColumn
+	csv     *csv.Writer
+	json    *json.Encoder
+
+	wroteHeader bool
+}
+
+// NewCSVExporter creates an exporter that writes CSV rows with a header line to w.
+func NewCSVExporter(w io.Writer, columns []ExportColumn) *EventExporter {
+	return &EventExporter{
+		columns: columns,
+		csv:     csv.NewWriter(w),
+	}
+}
+
+// NewJSONLExporter creates an exporter that writes one JSON object per event to w.
+func NewJSONLExporter(w io.Writer, columns []ExportColumn) *EventExporter {
+	return &EventExporter{
+		columns: columns,
+		json:    json.NewEncoder(w),
+	}
+}
+
+// Attach registers listeners on socket that export the specified events.
+func (e *EventExporter) Attach(socket *Socket, events ...EventName) []CallbackID {
+	ids := make([]CallbackID, len(events))
+	for i, name := range events {
+		ids[i] = socket.On(name, func(event Event) {
+			e.Export(event)
+		})
+	}
+	return ids
+}
+
+// Export writes a row for event.
+func (e *EventExporter) Export(event Event) error {
+	raw, err := event.rawData()
+	if err != nil {
+		return ErrDecodeFailed
+	}
+	data, err := decodeJSONValue(raw)
+	if err != nil {
+		return ErrDecodeFailed
+	}
+
+	values := make([]any, len(e.columns))
+	for i, column := range e.columns {
+		values[i], _ = lookupPath(data, column.Path)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	now := clock.Now()
+	if e.json != nil {
+		row := make(map[string]any, len(e.columns)+2)
+		row["time"] = now
+		row["event"] = event.Name
+		for i, column := range e.columns {
+			row[column.Name] = values[i]
+		}
+		return e.json.Encode(row)
+	}
+
+	if !e.wroteHeader {
+		header := []string{"time", "event"}
+		for _, column := range e.columns {
+			header = append(header, column.Name)
+		}
+		err = e.csv.Write(header)
+		if err != nil {
+			return err
+		}
+		e.wroteHeader = true
+	}
+	record := []string{now.Format(time.RFC3339Nano), string(event.Name)}
+	for _, value := range values {
+		record = append(record, formatCSVValue(value))
+	}
+	err = e.csv.Write(record)
+	if err != nil {
+		return err
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func formatCSVValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
+
+// lookupPath resolves a path in dot notation (see ExportColumn) in a decoded JSON value.
+func lookupPath(value any, path string) (any, bool) {
+	if path == "" {
+		return value, true
+	}
+	for _, segment := range strings.Split(path, ".") {
+		name, indices, err := parsePathSegment(segment)
+		if err != nil {
+			return nil, false
+		}
+		if name != "" {
+			obj, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if value, ok = obj[name]; !ok {
+				return nil, false
+			}
+		}
+		for _, index := range indices {
+			array, ok := value.([]any)
+			if !ok || index < 0 || index >= len(array) {
+				return nil, false
+			}
+			value = array[index]
+		}
+	}
+	return value, true
+}
+
+func parsePathSegment(segment string) (string, []int, error) {
+	name, rest, _ := strings.Cut(segment, "[")
+	if rest == "" {
+		return name, nil, nil
+	}
+	var indices []int
+	for _, part := range strings.Split("["+rest, "[")[1:] {
+		if !strings.HasSuffix(part, "]") {
+			return "", nil, fmt.Errorf("invalid path segment '%s'", segment)
+		}
+		index, err := strconv.Atoi(strings.TrimSuffix(part, "]"))
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid path segment '%s'", segment)
+		}
+		indices = append(indices, index)
+	}
+	return name, indices, nil
+}
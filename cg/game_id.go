@@ -0,0 +1,49 @@
+package cg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrGameNotFound    = errors.New("game not found")
+	ErrAmbiguousGameID = errors.New("ambiguous game ID")
+)
+
+// ResolveGameID expands a shortened game ID, e.g. the first 8 characters of the UUID, or a server-provided alias
+// to the full ID of a public game on the server at gameURL.
+// Exact ID and alias matches take precedence over prefix matches. Matching is case-insensitive.
+// If several games match, the returned error wraps ErrAmbiguousGameID and lists their IDs.
+// Private games cannot be resolved because they are not listed by the server.
+func ResolveGameID(gameURL, short string) (string, error) {
+	short = strings.TrimSpace(short)
+	if short == "" {
+		return "", ErrNoGameID
+	}
+	games, _, err := ListGames(gameURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to list games: %w", err)
+	}
+	return resolveGameID(games, short)
+}
+
+func resolveGameID(games []GameInfo, short string) (string, error) {
+	var matches []string
+	for _, game := range games {
+		if strings.EqualFold(game.ID, short) || (game.Alias != "" && strings.EqualFold(game.Alias, short)) {
+			return game.ID, nil
+		}
+		if len(game.ID) >= len(short) && strings.EqualFold(game.ID[:len(short)], short) {
+			matches = append(matches, game.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrGameNotFound, short)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%w: %s matches %s", ErrAmbiguousGameID, short, strings.Join(matches, ", "))
+	}
+}
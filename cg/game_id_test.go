@@ -0,0 +1,53 @@
+package cg_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+func TestResolveGameID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/games" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"private":1,"public":[
+			{"id":"3f2a9c1e-0000-4000-8000-000000000001","players":1,"alias":"lobby"},
+			{"id":"3f2a9c1e-0000-4000-8000-000000000002","players":2},
+			{"id":"7b01d4aa-0000-4000-8000-000000000003","players":0},
+			{"id":"lob","players":0}
+		]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		short    string
+		expected string
+		err      error
+	}{
+		{short: "7b01d4aa", expected: "7b01d4aa-0000-4000-8000-000000000003"},
+		{short: "7B01", expected: "7b01d4aa-0000-4000-8000-000000000003"},
+		{short: "3f2a9c1e-0000-4000-8000-000000000002", expected: "3f2a9c1e-0000-4000-8000-000000000002"},
+		{short: "Lobby", expected: "3f2a9c1e-0000-4000-8000-000000000001"},
+		{short: "lob", expected: "lob"},
+		{short: "3f2a9c1e", err: cg.ErrAmbiguousGameID},
+		{short: "ffff", err: cg.ErrGameNotFound},
+		{short: " ", err: cg.ErrNoGameID},
+	}
+	for _, test := range tests {
+		gameID, err := cg.ResolveGameID(server.URL, test.short)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("%q: expected %v, got %q, %v", test.short, test.err, gameID, err)
+			}
+			continue
+		}
+		if err != nil || gameID != test.expected {
+			t.Errorf("%q: expected %s, got %q, %v", test.short, test.expected, gameID, err)
+		}
+	}
+}
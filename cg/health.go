@@ -0,0 +1,67 @@
+package cg
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Health is the coarse state of a socket reported by Socket.Healthy.
+type Health int
+
+const (
+	// HealthOK means the websocket connection is open and the REST API responds.
+	HealthOK Health = iota
+	// HealthDegraded means the websocket connection is open but the REST API does not respond.
+	HealthDegraded
+	// HealthDisconnected means the websocket connection has been closed or lost.
+	HealthDisconnected
+)
+
+func (h Health) String() string {
+	switch h {
+	case HealthOK:
+		return "ok"
+	case HealthDegraded:
+		return "degraded"
+	case HealthDisconnected:
+		return "disconnected"
+	default:
+		return fmt.Sprintf("Health(%d)", int(h))
+	}
+}
+
+// HealthStatus is the result of a health check.
+type HealthStatus struct {
+	Health Health
+	// Latency is the duration of the request to the REST API. It is 0 if the socket is disconnected.
+	Latency time.Duration
+	// Err is the reason the socket is not healthy or nil.
+	Err error
+}
+
+// Healthy checks whether the connection is still alive and the server responds to a request to /api/info.
+// It is cheap enough to be used in liveness probes.
+func (s *Socket) Healthy(ctx context.Context) HealthStatus {
+	if err := s.Err(); err != nil {
+		return HealthStatus{Health: HealthDisconnected, Err: err}
+	}
+
+	start := clock.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL("http", s.tls, "%s/api/info", s.gameURL), nil)
+	if err != nil {
+		return HealthStatus{Health: HealthDegraded, Err: err}
+	}
+	resp, err := s.dialConfig.httpClient().Do(req)
+	latency := clock.Now().Sub(start)
+	if err != nil {
+		return HealthStatus{Health: HealthDegraded, Latency: latency, Err: err}
+	}
+	defer resp.Body.Close()
+	err = checkStatus(resp, "check health", http.StatusOK)
+	if err != nil {
+		return HealthStatus{Health: HealthDegraded, Latency: latency, Err: err}
+	}
+	return HealthStatus{Health: HealthOK, Latency: latency}
+}
@@ -0,0 +1,181 @@
+package cg
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	ErrHeartbeatTimeout = errors.New("no message received from the server within the heartbeat timeout")
+	ErrReadTimeout      = errors.New("no message received from the server within the read timeout")
+)
+
+// SetHeartbeatTimeout makes the socket ping the server regularly and fail with ErrHeartbeatTimeout
+// if nothing (including pongs) is received within timeout.
+// A timeout of 0 disables heartbeat detection, which is the default.
+// While the event loop is running, the new timeout applies from the next message or pong received from the server.
+func (s *Socket) SetHeartbeatTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&s.heartbeatTimeout, int64(timeout))
+	if timeout > 0 {
+		s.startPinging()
+	}
+}
+
+// SetPingInterval makes the socket ping the server every interval to measure the quality of the connection.
+// See HealthScore and LastPong. An interval of 0 disables pinging unless a heartbeat timeout is set,
+// in which case the server is pinged every half heartbeat timeout.
+func (s *Socket) SetPingInterval(interval time.Duration) {
+	atomic.StoreInt64(&s.pingInterval, int64(interval))
+	if interval > 0 {
+		s.startPinging()
+	}
+}
+
+// LastPong returns the time the last pong was received or the zero time if none was received yet.
+func (s *Socket) LastPong() time.Time {
+	return s.pings.lastPongTime()
+}
+
+// HealthScore returns the fraction of the last pings that were answered before the next ping was sent,
+// from 0 (no pongs) to 1 (all pongs received). It returns 1 if no ping has been sent yet.
+func (s *Socket) HealthScore() float64 {
+	return s.pings.score()
+}
+
+// restartPinging resumes pinging on a new connection if pinging is enabled.
+func (s *Socket) restartPinging() {
+	if s.currentPingInterval() > 0 {
+		s.startPinging()
+	}
+}
+
+func (s *Socket) startPinging() {
+	if atomic.CompareAndSwapInt32(&s.pinging, 0, 1) {
+		go s.pingLoop()
+	}
+}
+
+func (s *Socket) currentHeartbeatTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.heartbeatTimeout))
+}
+
+func (s *Socket) currentPingInterval() time.Duration {
+	if interval := time.Duration(atomic.LoadInt64(&s.pingInterval)); interval > 0 {
+		return interval
+	}
+	return s.currentHeartbeatTimeout() / 2
+}
+
+// handlePongs records the pongs received on conn and extends its read deadline.
+// It must be called before the listen loop of conn starts because pongs are handled by the reader.
+func (s *Socket) handlePongs(conn wsConnection) {
+	conn.SetPongHandler(func(string) error {
+		s.pings.pong(clock.Now())
+		return s.extendReadDeadline(conn)
+	})
+}
+
+// extendReadDeadline moves the read deadline of conn by the heartbeat timeout or, without heartbeat, by the read timeout.
+// The deadline is cleared if neither is set. It must only be called by the reader of conn.
+func (s *Socket) extendReadDeadline(conn wsConnection) error {
+	timeout := s.currentHeartbeatTimeout()
+	if timeout <= 0 {
+		timeout = s.readTimeout
+	}
+	if timeout <= 0 {
+		return conn.SetReadDeadline(time.Time{})
+	}
+	return conn.SetReadDeadline(time.Now().Add(timeout))
+}
+
+func (s *Socket) pingLoop() {
+	defer func() {
+		atomic.StoreInt32(&s.pinging, 0)
+		// Pinging may have been enabled again after the interval was read.
+		if s.currentPingInterval() > 0 && s.isRunning() {
+			s.startPinging()
+		}
+	}()
+	for s.isRunning() {
+		interval := s.currentPingInterval()
+		if interval <= 0 {
+			// The pong makes the listen loop clear the read deadline of a disabled heartbeat.
+			s.connection().WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second))
+			return
+		}
+		clock.Sleep(interval)
+		s.pings.ping()
+		s.connection().WriteControl(websocket.PingMessage, nil, time.Now().Add(interval))
+	}
+}
+
+// pingHistorySize is the number of pings HealthScore is based on.
+const pingHistorySize = 10
+
+// pingTracker records which pings were answered.
+type pingTracker struct {
+	lock     sync.Mutex
+	lastPong time.Time
+	awaiting bool
+	// history contains whether each of the last pings was answered, oldest first.
+	history []bool
+}
+
+// ping records a new ping. A previous ping still awaiting its pong counts as missed.
+func (t *pingTracker) ping() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.awaiting {
+		t.record(false)
+	}
+	t.awaiting = true
+}
+
+func (t *pingTracker) pong(now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.lastPong = now
+	if t.awaiting {
+		t.awaiting = false
+		t.record(true)
+	}
+}
+
+func (t *pingTracker) record(answered bool) {
+	t.history = append(t.history, answered)
+	if len(t.history) > pingHistorySize {
+		t.history = t.history[1:]
+	}
+}
+
+func (t *pingTracker) lastPongTime() time.Time {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.lastPong
+}
+
+func (t *pingTracker) score() float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.history) == 0 {
+		return 1
+	}
+	answered := 0
+	for _, ok := range t.history {
+		if ok {
+			answered++
+		}
+	}
+	return float64(answered) / float64(len(t.history))
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
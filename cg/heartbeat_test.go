@@ -0,0 +1,35 @@
+package cg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestHeartbeat(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice", cg.WithHeartbeatTimeout(100*time.Millisecond))
+	loopErr := make(chan error, 1)
+	go func() {
+		loopErr <- socket.RunEventLoop()
+	}()
+
+	waitFor(t, "pong", func() bool {
+		return !socket.LastPong().IsZero()
+	})
+	// Changing the heartbeat while the event loop is running must not race with the reader.
+	socket.SetHeartbeatTimeout(0)
+	time.Sleep(250 * time.Millisecond)
+	socket.SetHeartbeatTimeout(80 * time.Millisecond)
+	time.Sleep(250 * time.Millisecond)
+	if err := socket.Err(); err != nil {
+		t.Fatalf("expected the socket to stay connected, got %v", err)
+	}
+
+	socket.Close()
+	if err := <-loopErr; err != nil {
+		t.Fatalf("expected RunEventLoop to return nil, got %v", err)
+	}
+}
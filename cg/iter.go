@@ -0,0 +1,36 @@
+//go:build go1.23
+
+package cg
+
+import (
+	"context"
+	"iter"
+)
+
+// Events returns an iterator over the received events.
+// Registered event listeners are triggered before an event is yielded.
+// Iteration ends when the connection is closed normally. If ctx is canceled or the connection
+// is lost because of an error, the error is yielded as the last element.
+func (s *Socket) Events(ctx context.Context) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		eventChan, _ := s.channels()
+		for {
+			select {
+			case <-ctx.Done():
+				yield(Event{}, ctx.Err())
+				return
+			case event, ok := <-eventChan:
+				if !ok {
+					if err := s.loadErr(); err != ErrClosed {
+						yield(Event{}, err)
+					}
+					return
+				}
+				s.triggerEventListeners(event)
+				if !yield(event, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cg
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	neturl "net/url"
+	"strings"
+
+	"github.com/code-game-project/go-client/internal/qr"
+)
+
+var ErrInvalidJoinURL = errors.New("invalid join URL")
+
+// JoinURL returns the canonical invitation link to a game, e.g. `https://example.com/?game_id=…&join_secret=…`.
+// joinSecret is omitted if empty. The scheme is determined by probing the server for TLS.
+func JoinURL(gameURL, gameID, joinSecret string) string {
+	gameURL = trimURL(gameURL)
+	query := neturl.Values{}
+	query.Set("game_id", gameID)
+	if joinSecret != "" {
+		query.Set("join_secret", joinSecret)
+	}
+	return baseURL("http", isTLS(gameURL), "%s/", strings.TrimSuffix(gameURL, "/")) + "?" + query.Encode()
+}
+
+// ParseJoinURL extracts the game URL, game ID and join secret from a link created by JoinURL.
+func ParseJoinURL(link string) (gameURL, gameID, joinSecret string, err error) {
+	url, err := neturl.Parse(link)
+	if err != nil || url.Host == "" {
+		return "", "", "", ErrInvalidJoinURL
+	}
+	query := url.Query()
+	gameID = query.Get("game_id")
+	if gameID == "" {
+		return "", "", "", ErrInvalidJoinURL
+	}
+	return url.Host + strings.TrimSuffix(url.Path, "/"), gameID, query.Get("join_secret"), nil
+}
+
+// quietZone is the number of light modules surrounding a QR code.
+const quietZone = 4
+
+// WriteQRCodePNG renders text, e.g. a join URL, as a QR code PNG with scale pixels per module.
+func WriteQRCodePNG(w io.Writer, text string, scale int) error {
+	code, err := qr.Encode([]byte(text))
+	if err != nil {
+		return err
+	}
+	if scale < 1 {
+		scale = 1
+	}
+	size := (code.Size + 2*quietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+			mx, my := x/scale-quietZone, y/scale-quietZone
+			if mx >= 0 && my >= 0 && mx < code.Size && my < code.Size && code.Modules[my][mx] {
+				img.SetGray(x, y, color.Gray{Y: 0})
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// QRCodeASCII renders text, e.g. a join URL, as a QR code for terminals using Unicode half blocks.
+// Each character represents two modules stacked vertically. The code is drawn dark on light,
+// so terminals with a dark background display it correctly.
+func QRCodeASCII(text string) (string, error) {
+	code, err := qr.Encode([]byte(text))
+	if err != nil {
+		return "", err
+	}
+	dark := func(x, y int) bool {
+		x -= quietZone
+		y -= quietZone
+		return x >= 0 && y >= 0 && x < code.Size && y < code.Size && code.Modules[y][x]
+	}
+
+	var builder strings.Builder
+	size := code.Size + 2*quietZone
+	for y := 0; y < size; y += 2 {
+		for x := 0; x < size; x++ {
+			top, bottom := dark(x, y), dark(x, y+1)
+			switch {
+			case !top && !bottom:
+				builder.WriteRune('█')
+			case !top && bottom:
+				builder.WriteRune('▀')
+			case top && !bottom:
+				builder.WriteRune('▄')
+			default:
+				builder.WriteRune(' ')
+			}
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String(), nil
+}
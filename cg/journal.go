@@ -0,0 +1,223 @@
+package cg
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type JournalEntryKind string
+
+const (
+	JournalEvent   JournalEntryKind = "event"
+	JournalCommand JournalEntryKind = "command"
+)
+
+// JournalEntry is a single event or command recorded by a journal.
+type JournalEntry struct {
+	Time     time.Time        `json:"time"`
+	Kind     JournalEntryKind `json:"kind"`
+	GameURL  string           `json:"game_url"`
+	GameID   string           `json:"game_id"`
+	PlayerID string           `json:"player_id,omitempty"`
+	Name     string           `json:"name"`
+	Data     json.RawMessage  `json:"data"`
+}
+
+// JournalQuery selects journal entries. Zero fields match all entries.
+type JournalQuery struct {
+	Kind   JournalEntryKind
+	GameID string
+	Name   string
+	Since  time.Time
+	Until  time.Time
+}
+
+// Matches reports whether entry is selected by the query.
+func (q JournalQuery) Matches(entry JournalEntry) bool {
+	return (q.Kind == "" || entry.Kind == q.Kind) &&
+		(q.GameID == "" || entry.GameID == q.GameID) &&
+		(q.Name == "" || entry.Name == q.Name) &&
+		(q.Since.IsZero() || !entry.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || entry.Time.Before(q.Until))
+}
+
+// Journal persistently records events and commands.
+type Journal interface {
+	Write(entry JournalEntry) error
+	Close() error
+}
+
+// SetJournal makes the socket record every received event and sent command in journal.
+// Errors returned by the journal are ignored. Pass nil to disable journaling.
+func (s *Socket) SetJournal(journal Journal) {
+	s.journal = journal
+}
+
+func (s *Socket) writeJournal(t time.Time, kind JournalEntryKind, name string, data json.RawMessage) {
+	if s.journal == nil {
+		return
+	}
+	s.journal.Write(JournalEntry{
+		Time:     t,
+		Kind:     kind,
+		GameURL:  s.gameURL,
+		GameID:   s.gameID,
+		PlayerID: s.playerID,
+		Name:     name,
+		Data:     data,
+	})
+}
+
+// JSONLJournal writes journal entries as JSON lines.
+type JSONLJournal struct {
+	lock    sync.Mutex
+	w       io.Writer
+	encoder *json.Encoder
+}
+
+// NewJSONLJournal creates a journal that writes to w.
+// If w implements io.Closer, it is closed when the journal is closed.
+func NewJSONLJournal(w io.Writer) *JSONLJournal {
+	return &JSONLJournal{
+		w:       w,
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// OpenJSONLJournal creates a journal that appends to the file at path.
+func OpenJSONLJournal(path string) (*JSONLJournal, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewJSONLJournal(file), nil
+}
+
+func (j *JSONLJournal) Write(entry JournalEntry) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.encoder.Encode(entry)
+}
+
+func (j *JSONLJournal) Close() error {
+	if closer, ok := j.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// QueryJSONLJournal reads all entries matching query from a JSONL journal.
+func QueryJSONLJournal(r io.Reader, query JournalQuery) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry JournalEntry
+		err := json.Unmarshal([]byte(line), &entry)
+		if err != nil {
+			return nil, ErrDecodeFailed
+		}
+		if query.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// SQLJournal stores journal entries in the cg_journal table of an SQL database.
+// The queries are written for SQLite; the driver has to be registered by the application.
+type SQLJournal struct {
+	db *sql.DB
+}
+
+// NewSQLJournal creates the cg_journal table if it does not exist yet.
+func NewSQLJournal(db *sql.DB) (*SQLJournal, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS cg_journal (
+		time INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		game_url TEXT NOT NULL,
+		game_id TEXT NOT NULL,
+		player_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		data TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLJournal{db: db}, nil
+}
+
+func (j *SQLJournal) Write(entry JournalEntry) error {
+	_, err := j.db.Exec("INSERT INTO cg_journal (time, kind, game_url, game_id, player_id, name, data) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		entry.Time.UnixNano(), string(entry.Kind), entry.GameURL, entry.GameID, entry.PlayerID, entry.Name, string(entry.Data))
+	return err
+}
+
+// Close does not close the underlying database.
+func (j *SQLJournal) Close() error {
+	return nil
+}
+
+// Query returns all entries matching query ordered by time.
+func (j *SQLJournal) Query(query JournalQuery) ([]JournalEntry, error) {
+	var conditions []string
+	var args []any
+	if query.Kind != "" {
+		conditions = append(conditions, "kind = ?")
+		args = append(args, string(query.Kind))
+	}
+	if query.GameID != "" {
+		conditions = append(conditions, "game_id = ?")
+		args = append(args, query.GameID)
+	}
+	if query.Name != "" {
+		conditions = append(conditions, "name = ?")
+		args = append(args, query.Name)
+	}
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, query.Since.UnixNano())
+	}
+	if !query.Until.IsZero() {
+		conditions = append(conditions, "time < ?")
+		args = append(args, query.Until.UnixNano())
+	}
+
+	statement := "SELECT time, kind, game_url, game_id, player_id, name, data FROM cg_journal"
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	statement += " ORDER BY time"
+
+	rows, err := j.db.Query(statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []JournalEntry
+	for rows.Next() {
+		var entry JournalEntry
+		var timestamp int64
+		var kind, data string
+		err = rows.Scan(&timestamp, &kind, &entry.GameURL, &entry.GameID, &entry.PlayerID, &entry.Name, &data)
+		if err != nil {
+			return nil, err
+		}
+		entry.Time = time.Unix(0, timestamp)
+		entry.Kind = JournalEntryKind(kind)
+		entry.Data = json.RawMessage(data)
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,45 @@
+package cg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestLifecycleCallbacks(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+	closed := make(chan struct{}, 1)
+	socket.OnClose(func() {
+		closed <- struct{}{}
+	})
+	socket.OnDisconnect(func(err error) {
+		t.Errorf("expected OnClose, got OnDisconnect with %v", err)
+	})
+
+	stop := make(chan struct{})
+	registered := make(chan struct{})
+	go func() {
+		defer close(registered)
+		// Registering callbacks while the listen goroutine triggers them must not race.
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			socket.RemoveCallback(socket.OnClose(func() {}))
+			socket.RemoveCallback(socket.OnDisconnect(func(error) {}))
+		}
+	}()
+
+	server.DisconnectAll()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected OnClose to be triggered")
+	}
+	close(stop)
+	<-registered
+}
@@ -0,0 +1,34 @@
+package cg
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// WaitForPlayers blocks until at least n players are in the game or ctx expires.
+// The player list is refreshed whenever a NewPlayerEvent or PlayerLeftEvent is received.
+func (s *Socket) WaitForPlayers(ctx context.Context, n int) error {
+	atomic.AddInt32(&s.playerWaiters, 1)
+	defer atomic.AddInt32(&s.playerWaiters, -1)
+
+	// Players might have joined before the first event could trigger a refresh.
+	s.usernames.refresh()
+
+	for {
+		count, changed := s.usernames.playerCount()
+		if count >= n {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// PlayerCount returns the number of players in the game as of the last refresh of the player list.
+func (s *Socket) PlayerCount() int {
+	count, _ := s.usernames.playerCount()
+	return count
+}
@@ -0,0 +1,53 @@
+package cg
+
+import "container/list"
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+// lruCache is a size-bounded map that evicts the least recently used entry when full.
+// It is not safe for concurrent use.
+type lruCache[V any] struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+// newLRUCache returns a cache holding at most capacity entries. A capacity <= 0 means no limit.
+func newLRUCache[V any](capacity int) *lruCache[V] {
+	return &lruCache[V]{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *lruCache[V]) get(key string) (V, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[V]).value, true
+}
+
+func (c *lruCache[V]) put(key string, value V) {
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*lruEntry[V]).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value})
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+func (c *lruCache[V]) len() int {
+	return c.order.Len()
+}
@@ -0,0 +1,39 @@
+package cg
+
+import "testing"
+
+func TestLRUCache(t *testing.T) {
+	cache := newLRUCache[int](2)
+	cache.put("a", 1)
+	cache.put("b", 2)
+	cache.get("a")
+	cache.put("c", 3)
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	for key, expected := range map[string]int{"a": 1, "c": 3} {
+		if value, ok := cache.get(key); !ok || value != expected {
+			t.Errorf("expected %s=%d, got %d (%t)", key, expected, value, ok)
+		}
+	}
+
+	cache.put("a", 10)
+	cache.put("d", 4)
+	if value, ok := cache.get("a"); !ok || value != 10 {
+		t.Errorf("expected the updated entry to be kept, got %d (%t)", value, ok)
+	}
+	if n := cache.len(); n != 2 {
+		t.Errorf("expected 2 entries, got %d", n)
+	}
+}
+
+func TestLRUCacheWithoutLimit(t *testing.T) {
+	cache := newLRUCache[int](0)
+	for i := 0; i < 100; i++ {
+		cache.put(string(rune('a'+i)), i)
+	}
+	if n := cache.len(); n != 100 {
+		t.Errorf("expected 100 entries, got %d", n)
+	}
+}
@@ -0,0 +1,47 @@
+package cg
+
+import "fmt"
+
+// MatchOptions configures MatchOrCreate.
+type MatchOptions struct {
+	// MaxPlayers only matches games with fewer players. 0 means no limit.
+	MaxPlayers int
+	// Filter only matches games for which it returns true. Optional.
+	Filter func(game GameInfo) bool
+	// Config is used when a new game has to be created.
+	Config any
+}
+
+// MatchOrCreate joins the first public game on the server matching opts or creates a new public game otherwise.
+// The returned socket is connected to the newly created player.
+func MatchOrCreate(gameURL, username string, opts MatchOptions) (*Socket, error) {
+	games, _, err := ListGames(gameURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list games: %w", err)
+	}
+
+	for _, game := range games {
+		if opts.MaxPlayers > 0 && game.Players >= opts.MaxPlayers {
+			continue
+		}
+		if opts.Filter != nil && !opts.Filter(game) {
+			continue
+		}
+		playerID, playerSecret, err := JoinGame(gameURL, game.ID, username, "")
+		if err != nil {
+			// The game might have filled up or ended in the meantime.
+			continue
+		}
+		return Connect(gameURL, game.ID, playerID, playerSecret)
+	}
+
+	gameID, _, err := CreateGame(gameURL, true, false, opts.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create game: %w", err)
+	}
+	playerID, playerSecret, err := JoinGame(gameURL, gameID, username, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to join game: %w", err)
+	}
+	return Connect(gameURL, gameID, playerID, playerSecret)
+}
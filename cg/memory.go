@@ -0,0 +1,32 @@
+package cg
+
+import "sync/atomic"
+
+// maxPooledBufferSize prevents single huge messages from being retained by bufferPool. It is accessed atomically.
+var maxPooledBufferSize int64 = 64 * 1024
+
+// SetMaxPooledBufferSize sets the capacity up to which read buffers are reused for subsequent messages.
+// Larger buffers are released to the garbage collector after use. 0 disables buffer reuse. Defaults to 64 KiB.
+func SetMaxPooledBufferSize(size int) {
+	atomic.StoreInt64(&maxPooledBufferSize, int64(size))
+}
+
+func reuseBuffer(capacity int) bool {
+	return int64(capacity) <= atomic.LoadInt64(&maxPooledBufferSize)
+}
+
+// SetDropRawData makes the socket release the raw data of events that were decoded into a type registered
+// with RegisterEventType before they are queued. This reduces the memory held by queued events and by
+// listeners that keep events around. Event.Data is nil for such events and UnmarshalData encodes the decoded value again,
+// so fields that are not part of the registered type are lost. Standard events always keep their raw data.
+// The raw data is still recorded in the journal.
+func (s *Socket) SetDropRawData(enable bool) {
+	s.dropRawData = enable
+}
+
+// WithDropRawData is equivalent to calling SetDropRawData after connecting.
+func WithDropRawData(enable bool) Option {
+	return func(config *socketConfig) {
+		config.dropRawData = enable
+	}
+}
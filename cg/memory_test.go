@@ -0,0 +1,51 @@
+package cg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+type droppedMove struct {
+	X int `json:"x"`
+}
+
+func init() {
+	cg.RegisterEventType[droppedMove]("dropped_move")
+}
+
+func TestDropRawData(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice", cg.WithDropRawData(true))
+
+	joined := make(chan [2]string, 1)
+	socket.OnPlayerJoined(func(playerID, username string) {
+		joined <- [2]string{playerID, username}
+	})
+
+	server.Emit(cg.NewPlayerEvent, map[string]string{"player_id": "player_2", "username": "bob"})
+	event := cgtest.ExpectEvent(t, socket, cg.NewPlayerEvent, time.Second)
+	if event.Data == nil {
+		t.Error("expected standard events to keep their raw data")
+	}
+	select {
+	case player := <-joined:
+		if player != [2]string{"player_2", "bob"} {
+			t.Errorf("expected OnPlayerJoined with player_2 bob, got %v", player)
+		}
+	default:
+		t.Fatal("expected OnPlayerJoined to be triggered")
+	}
+
+	server.Emit("dropped_move", droppedMove{X: 3})
+	event = cgtest.ExpectEvent(t, socket, "dropped_move", time.Second)
+	if event.Data != nil {
+		t.Error("expected the raw data of registered events to be dropped")
+	}
+	var move droppedMove
+	if err := event.UnmarshalData(&move); err != nil || move.X != 3 {
+		t.Errorf("expected x = 3, got %+v (%v)", move, err)
+	}
+}
@@ -0,0 +1,154 @@
+package cg
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GameEvent is an event tagged with the ID of the game it was received from.
+// GameID is empty for events generated locally by a multiplexed connection, e.g. SequenceGapEvent.
+type GameEvent struct {
+	GameID string
+	Event
+}
+
+// MultiSpectator spectates several games at once and merges their events into a single stream.
+type MultiSpectator struct {
+	sockets map[string]*Socket
+	events  chan GameEvent
+	closing chan struct{}
+	wg      sync.WaitGroup
+
+	closeOnce sync.Once
+}
+
+var spectatorBackoff = ExponentialBackoff{Initial: 500 * time.Millisecond, Max: 30 * time.Second, Jitter: 0.2}
+
+// SpectateAll spectates all games in gameIDs on the server at gameURL.
+// Connections that are lost because of an error are re-established with exponential backoff.
+// Games that are closed normally by the server are no longer watched.
+// See SpectateMultiplexed to share a single connection between all games.
+func SpectateAll(gameURL string, gameIDs []string) (*MultiSpectator, error) {
+	m := newMultiSpectator(gameIDs)
+	for _, gameID := range gameIDs {
+		socket, err := Dial(gameURL, WithGame(gameID))
+		if err != nil {
+			m.Close()
+			return nil, fmt.Errorf("failed to spectate %s: %w", gameID, err)
+		}
+		m.sockets[gameID] = socket
+	}
+
+	for gameID, socket := range m.sockets {
+		m.wg.Add(1)
+		go m.forward(gameID, socket)
+	}
+	m.closeWhenDone()
+	return m, nil
+}
+
+// SpectateMultiplexed is like SpectateAll but receives the events of all games over a single connection
+// if the server advertises support with ServerInfo.Multiplex. Otherwise, or for a single game, it falls back to SpectateAll.
+// A multiplexed connection is shared by all games, so Socket returns the same socket for every game
+// and its listeners receive the events of all games. The connection is re-established like in SpectateAll.
+func SpectateMultiplexed(gameURL string, gameIDs []string) (*MultiSpectator, error) {
+	trimmedURL := trimURL(gameURL)
+	config := newDialConfig(trimmedURL, nil)
+	tls := probeTLS(trimmedURL, config)
+	info, err := sharedRESTCache(trimmedURL).get("info", func() (any, error) {
+		return fetchInfo(config.httpClient(), trimmedURL, tls)
+	})
+	if err != nil || !info.(ServerInfo).Multiplex || len(gameIDs) < 2 {
+		return SpectateAll(gameURL, gameIDs)
+	}
+
+	socket, err := Dial(gameURL, WithTLS(tls), withMultiplexedGames(gameIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to spectate %d games: %w", len(gameIDs), err)
+	}
+	m := newMultiSpectator(gameIDs)
+	for _, gameID := range gameIDs {
+		m.sockets[gameID] = socket
+	}
+	m.wg.Add(1)
+	go m.forward("", socket)
+	m.closeWhenDone()
+	return m, nil
+}
+
+func newMultiSpectator(gameIDs []string) *MultiSpectator {
+	return &MultiSpectator{
+		sockets: make(map[string]*Socket, len(gameIDs)),
+		events:  make(chan GameEvent, 10*len(gameIDs)),
+		closing: make(chan struct{}),
+	}
+}
+
+func (m *MultiSpectator) closeWhenDone() {
+	go func() {
+		m.wg.Wait()
+		close(m.events)
+	}()
+}
+
+// Events returns the merged event stream. The channel is closed once no game is watched anymore.
+// Event listeners registered on the individual sockets are triggered before an event is forwarded.
+func (m *MultiSpectator) Events() <-chan GameEvent {
+	return m.events
+}
+
+// Socket returns the spectator socket of gameID or nil if the game is not watched.
+func (m *MultiSpectator) Socket(gameID string) *Socket {
+	return m.sockets[gameID]
+}
+
+// Close stops spectating all games.
+func (m *MultiSpectator) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.closing)
+		for _, socket := range m.sockets {
+			socket.Close()
+		}
+	})
+	return nil
+}
+
+// forward passes the events of socket to m.events. Events of a multiplexed socket, for which gameID is empty,
+// are tagged with the game they were received from.
+func (m *MultiSpectator) forward(gameID string, socket *Socket) {
+	defer m.wg.Done()
+	attempt := 0
+	for {
+		eventChan, _ := socket.channels()
+		for event := range eventChan {
+			socket.triggerEventListeners(event)
+			id := gameID
+			if id == "" {
+				id = event.gameID
+			}
+			select {
+			case m.events <- GameEvent{GameID: id, Event: event}:
+			case <-m.closing:
+				return
+			}
+			attempt = 0
+		}
+
+		if socket.loadErr() == ErrClosed {
+			return
+		}
+
+		for {
+			attempt++
+			select {
+			case <-m.closing:
+				return
+			case <-clock.After(spectatorBackoff.NextDelay(attempt)):
+			}
+			if socket.Reconnect() == nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cg_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/gorilla/websocket"
+)
+
+type multiplexServer struct {
+	*httptest.Server
+	lock sync.Mutex
+	// connections contains the game IDs of every websocket connection.
+	connections [][]string
+}
+
+// newMultiplexServer starts a server sending one tick event for every spectated game.
+// Multiplexed spectating is only supported if multiplex is true.
+func newMultiplexServer(t *testing.T, multiplex bool) *multiplexServer {
+	s := &multiplexServer{}
+	upgrader := websocket.Upgrader{}
+	stream := func(w http.ResponseWriter, r *http.Request, gameIDs []string, tagged bool) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.lock.Lock()
+		s.connections = append(s.connections, gameIDs)
+		s.lock.Unlock()
+		for _, gameID := range gameIDs {
+			event := map[string]any{"name": "tick", "data": gameID}
+			if tagged {
+				event["game_id"] = gameID
+			}
+			msg, _ := json.Marshal(event)
+			conn.WriteMessage(websocket.TextMessage, msg)
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case r.URL.Path == "/api/info":
+			json.NewEncoder(w).Encode(cg.ServerInfo{Name: "multiplex", CGVersion: cg.CGVersion, Multiplex: multiplex})
+		case r.URL.Path == "/api/spectate" && multiplex:
+			stream(w, r, r.URL.Query()["game_id"], true)
+		case len(parts) == 4 && parts[3] == "spectate":
+			stream(w, r, []string{parts[2]}, false)
+		case len(parts) == 4 && parts[3] == "players":
+			w.Write([]byte("{}"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *multiplexServer) received() [][]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([][]string(nil), s.connections...)
+}
+
+func expectGameEvents(t *testing.T, spectator *cg.MultiSpectator, gameIDs ...string) {
+	t.Helper()
+	received := make(map[string]bool)
+	for range gameIDs {
+		select {
+		case event := <-spectator.Events():
+			var data string
+			event.UnmarshalData(&data)
+			if data != event.GameID {
+				t.Errorf("expected the event of game %s to be tagged with its game, got %q", data, event.GameID)
+			}
+			received[event.GameID] = true
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for events, got %v", received)
+		}
+	}
+	for _, gameID := range gameIDs {
+		if !received[gameID] {
+			t.Errorf("expected an event of game %s, got %v", gameID, received)
+		}
+	}
+}
+
+func TestSpectateMultiplexed(t *testing.T) {
+	server := newMultiplexServer(t, true)
+	spectator, err := cg.SpectateMultiplexed(server.URL, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("failed to spectate: %s", err)
+	}
+	defer spectator.Close()
+
+	expectGameEvents(t, spectator, "a", "b", "c")
+	connections := server.received()
+	if len(connections) != 1 || strings.Join(connections[0], ",") != "a,b,c" {
+		t.Errorf("expected a single connection for games a, b and c, got %v", connections)
+	}
+	if spectator.Socket("a") != spectator.Socket("c") {
+		t.Error("expected all games to share the socket")
+	}
+}
+
+func TestSpectateMultiplexedFallback(t *testing.T) {
+	server := newMultiplexServer(t, false)
+	spectator, err := cg.SpectateMultiplexed(server.URL, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("failed to spectate: %s", err)
+	}
+	defer spectator.Close()
+
+	expectGameEvents(t, spectator, "a", "b")
+	if connections := server.received(); len(connections) != 2 {
+		t.Errorf("expected a connection per game, got %v", connections)
+	}
+	if spectator.Socket("a") == spectator.Socket("b") {
+		t.Error("expected a socket per game")
+	}
+}
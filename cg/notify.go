@@ -0,0 +1,110 @@
+package cg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// NotificationService is a chat service with incoming webhooks.
+type NotificationService string
+
+const (
+	NotifyDiscord NotificationService = "discord"
+	NotifySlack   NotificationService = "slack"
+)
+
+// discordMaxContent is the maximum length of a Discord message.
+const discordMaxContent = 2000
+
+// NotificationData is passed to notification templates.
+type NotificationData struct {
+	GameURL string
+	GameID  string
+	Event   Event
+	// Data is the event data decoded into generic JSON values, e.g. {{.Data.winner}}.
+	Data any
+	// Username is the username of the player who caused the event or an empty string.
+	Username string
+}
+
+// DefaultNotificationTemplates returns templates for the standard events, which can be extended with game-specific events.
+func DefaultNotificationTemplates() map[EventName]string {
+	return map[EventName]string{
+		NewPlayerEvent:  "{{.Username}} joined game {{.GameID}}.",
+		PlayerLeftEvent: "{{.Username}} left game {{.GameID}}.",
+	}
+}
+
+// NewNotificationSink posts a chat message to the incoming webhook at webhookURL of service for every event received by socket
+// that has a template. Templates use the text/template syntax and are executed with NotificationData.
+// Delivery is handled by a Webhook, which can be used to configure retries, error handling and the HTTP client.
+// Messages are sent without the client identification and credentials used for the game server.
+func NewNotificationSink(socket *Socket, service NotificationService, webhookURL string, templates map[EventName]string) (*Webhook, error) {
+	if service != NotifyDiscord && service != NotifySlack {
+		return nil, fmt.Errorf("unsupported notification service: %s", service)
+	}
+	if len(templates) == 0 {
+		return nil, errors.New("no notification templates")
+	}
+
+	parsed := make(map[EventName]*template.Template, len(templates))
+	names := make([]EventName, 0, len(templates))
+	for name, text := range templates {
+		tmpl, err := template.New(string(name)).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for event '%s': %w", name, err)
+		}
+		parsed[name] = tmpl
+		names = append(names, name)
+	}
+
+	w := newWebhook(socket, webhookURL, names)
+	w.payload = func(event Event) ([]byte, error) {
+		text, err := renderNotification(socket, parsed[event.Name], event)
+		if err != nil {
+			return nil, err
+		}
+		if service == NotifySlack {
+			return json.Marshal(map[string]string{"text": text})
+		}
+		if len(text) > discordMaxContent {
+			text = strings.ToValidUTF8(text[:discordMaxContent-3], "") + "..."
+		}
+		return json.Marshal(map[string]string{"content": text})
+	}
+	return w, nil
+}
+
+func renderNotification(socket *Socket, tmpl *template.Template, event Event) (string, error) {
+	data := NotificationData{
+		GameURL: socket.gameURL,
+		GameID:  socket.gameID,
+		Event:   event,
+	}
+	raw, err := event.rawData()
+	if err != nil {
+		return "", err
+	}
+	json.Unmarshal(raw, &data.Data)
+
+	if event.Name == NewPlayerEvent {
+		var newPlayer NewPlayerEventData
+		if json.Unmarshal(raw, &newPlayer) == nil {
+			data.Username = newPlayer.Username
+		}
+	}
+	if data.Username == "" && event.Origin != "" && !event.FromServer() {
+		data.Username = socket.Username(event.Origin)
+	}
+
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, data)
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
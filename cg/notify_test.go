@@ -0,0 +1,65 @@
+package cg_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+type notificationRequest struct {
+	header http.Header
+	body   map[string]string
+}
+
+func TestNotificationSink(t *testing.T) {
+	tests := []struct {
+		service cg.NotificationService
+		field   string
+	}{
+		{service: cg.NotifyDiscord, field: "content"},
+		{service: cg.NotifySlack, field: "text"},
+	}
+	for _, test := range tests {
+		t.Run(string(test.service), func(t *testing.T) {
+			requests := make(chan notificationRequest, 10)
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				data, _ := io.ReadAll(req.Body)
+				var body map[string]string
+				json.Unmarshal(data, &body)
+				requests <- notificationRequest{header: req.Header.Clone(), body: body}
+			}))
+			defer receiver.Close()
+
+			server := cgtest.NewServer(t)
+			socket := dialPlayer(t, server, "alice", cg.WithDialOptions(cg.WithTokenSource(cg.StaticToken("secret"))))
+			sink, err := cg.NewNotificationSink(socket, test.service, receiver.URL, map[cg.EventName]string{
+				"game_over": "{{.Data.winner}} won game {{.GameID}}.",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			server.Emit("game_over", map[string]string{"winner": "alice"})
+			cgtest.ExpectEvent(t, socket, "game_over", time.Second)
+			sink.Close()
+
+			request := <-requests
+			if want := "alice won game " + server.GameID + "."; request.body[test.field] != want {
+				t.Errorf("expected %s %q, got %v", test.field, want, request.body)
+			}
+			if value := request.header.Get("Content-Type"); value != "application/json" {
+				t.Errorf("expected Content-Type application/json, got %q", value)
+			}
+			for _, name := range []string{"Authorization", "X-Codegame-Client"} {
+				if value := request.header.Get(name); value != "" {
+					t.Errorf("expected no header %s, got %q", name, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package cg
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNoGameID = errors.New("no game ID specified")
+
+// Logger is implemented by *log.Logger and most structured logging adapters.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// ReconnectPolicy controls automatic reconnection after the connection is lost because of an error.
+// Normal closes are never retried.
+type ReconnectPolicy struct {
+	// MaxAttempts is the maximum number of consecutive reconnection attempts. 0 disables automatic reconnection.
+	MaxAttempts int
+	// Delay is the time to wait before the first attempt. It is doubled after every failed attempt.
+	Delay time.Duration
+	// MaxDelay caps the delay between attempts. 0 means no limit.
+	MaxDelay time.Duration
+	// Backoff overrides Delay and MaxDelay if set.
+	Backoff BackoffStrategy
+}
+
+func (p ReconnectPolicy) backoff() BackoffStrategy {
+	if p.Backoff != nil {
+		return p.Backoff
+	}
+	return ExponentialBackoff{Initial: p.Delay, Max: p.MaxDelay}
+}
+
+// Option configures a socket created with Dial.
+type Option func(config *socketConfig)
+
+type socketConfig struct {
+	gameID       string
+	playerID     string
+	playerSecret string
+	credentials  CredentialsProvider
+
+	tls         *bool
+	dialOptions []DialOption
+
+	eventBufferSize  int
+	heartbeatTimeout time.Duration
+	strictDecoding   bool
+	suppressEchoes   bool
+	dropRawData      bool
+	eventTTL         time.Duration
+	sampling         map[EventName]int
+	aggregation      map[EventName]time.Duration
+	highWatermark    int
+	lowWatermark     int
+	prefetch         bool
+	journal          Journal
+	logger           Logger
+	reconnect        ReconnectPolicy
+	checkEvents      bool
+	usernameCache    int
+	progress         ProgressFunc
+	readTimeout      time.Duration
+	writeTimeout     time.Duration
+	pingInterval     time.Duration
+	multiplexGames   []string
+}
+
+// withMultiplexedGames makes the socket spectate gameIDs over one multiplexed connection instead of a single game.
+// The players of the games are not fetched. See SpectateMultiplexed.
+func withMultiplexedGames(gameIDs []string) Option {
+	return func(config *socketConfig) {
+		config.multiplexGames = gameIDs
+	}
+}
+
+// WithGame selects the game to connect to. This option is required.
+func WithGame(gameID string) Option {
+	return func(config *socketConfig) {
+		config.gameID = gameID
+	}
+}
+
+// WithPlayer connects as the player instead of spectating.
+func WithPlayer(playerID, playerSecret string) Option {
+	return func(config *socketConfig) {
+		config.playerID = playerID
+		config.playerSecret = playerSecret
+	}
+}
+
+// WithCredentials connects as the player using the secret supplied by provider.
+func WithCredentials(playerID string, provider CredentialsProvider) Option {
+	return func(config *socketConfig) {
+		config.playerID = playerID
+		config.credentials = provider
+	}
+}
+
+// WithTLS forces the use of TLS instead of probing the server.
+func WithTLS(enable bool) Option {
+	return func(config *socketConfig) {
+		config.tls = &enable
+	}
+}
+
+// WithDialOptions configures how the websocket connection is established.
+func WithDialOptions(opts ...DialOption) Option {
+	return func(config *socketConfig) {
+		config.dialOptions = append(config.dialOptions, opts...)
+	}
+}
+
+// WithEventBufferSize sets the number of received events that are buffered before the listen loop blocks. Default: 10.
+func WithEventBufferSize(size int) Option {
+	return func(config *socketConfig) {
+		config.eventBufferSize = size
+	}
+}
+
+// WithUsernameCacheSize sets the maximum number of cached usernames. A size <= 0 means no limit. Default: 1024.
+func WithUsernameCacheSize(size int) Option {
+	return func(config *socketConfig) {
+		config.usernameCache = size
+	}
+}
+
+// WithConnectTimeout limits the time for establishing the websocket connection including dialing and TLS.
+// A timeout of 0 means no limit. It is a shortcut for WithDialOptions(WithHandshakeTimeout(timeout)).
+func WithConnectTimeout(timeout time.Duration) Option {
+	return WithDialOptions(WithHandshakeTimeout(timeout))
+}
+
+// WithReadTimeout makes the socket fail with ErrReadTimeout if no message is received for timeout.
+// Unlike WithHeartbeatTimeout, no pings are sent, so the server must send messages on its own.
+// A timeout of 0 means no limit, which is the default. The heartbeat timeout takes precedence if both are set.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(config *socketConfig) {
+		config.readTimeout = timeout
+	}
+}
+
+// WithWriteTimeout makes Send fail if a command cannot be written within timeout.
+// A timeout of 0 means no limit, which is the default.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(config *socketConfig) {
+		config.writeTimeout = timeout
+	}
+}
+
+// WithHeartbeatTimeout is equivalent to calling SetHeartbeatTimeout after connecting.
+func WithHeartbeatTimeout(timeout time.Duration) Option {
+	return func(config *socketConfig) {
+		config.heartbeatTimeout = timeout
+	}
+}
+
+// WithPingInterval is equivalent to calling SetPingInterval after connecting.
+func WithPingInterval(interval time.Duration) Option {
+	return func(config *socketConfig) {
+		config.pingInterval = interval
+	}
+}
+
+// WithStrictDecoding is equivalent to calling SetStrictDecoding after connecting.
+func WithStrictDecoding(enable bool) Option {
+	return func(config *socketConfig) {
+		config.strictDecoding = enable
+	}
+}
+
+// WithPrefetchUsernames is equivalent to calling SetPrefetchUsernames after connecting.
+func WithPrefetchUsernames(enable bool) Option {
+	return func(config *socketConfig) {
+		config.prefetch = enable
+	}
+}
+
+// WithJournal is equivalent to calling SetJournal before any event is received.
+func WithJournal(journal Journal) Option {
+	return func(config *socketConfig) {
+		config.journal = journal
+	}
+}
+
+// WithLogger makes the socket log noteworthy occurrences like reconnection attempts to logger.
+// By default nothing is logged.
+func WithLogger(logger Logger) Option {
+	return func(config *socketConfig) {
+		config.logger = logger
+	}
+}
+
+// WithEventsCheck fetches the CGE file of the game and logs a warning through the logger
+// whenever a listener is registered for an event the game does not define.
+// It has no effect without WithLogger.
+func WithEventsCheck() Option {
+	return func(config *socketConfig) {
+		config.checkEvents = true
+	}
+}
+
+// WithReconnectPolicy enables automatic reconnection. See ReconnectPolicy.
+func WithReconnectPolicy(policy ReconnectPolicy) Option {
+	return func(config *socketConfig) {
+		config.reconnect = policy
+	}
+}
+
+// Dial connects to a game on the server at gameURL as configured by opts.
+// Without WithPlayer or WithCredentials the socket connects as a spectator.
+// The event protocol is selected by the CodeGame version the server advertises, so that servers
+// of older versions that wrap their events with origin and target metadata are supported as well.
+func Dial(gameURL string, opts ...Option) (*Socket, error) {
+	config := socketConfig{
+		eventBufferSize: 10,
+		usernameCache:   defaultUsernameCacheSize,
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.gameID == "" && len(config.multiplexGames) == 0 {
+		return nil, ErrNoGameID
+	}
+
+	if config.credentials != nil && config.playerSecret == "" {
+		config.reportProgress(StageCredentials, nil)
+		secret, err := config.credentials.GetPlayerSecret(gameURL, config.gameID, config.playerID)
+		if err != nil {
+			err = newConnectError(StageCredentials, fmt.Errorf("failed to get secret of player %s: %w", config.playerID, err))
+			config.reportProgress(StageCredentials, err)
+			return nil, err
+		}
+		config.playerSecret = secret
+	}
+
+	gameURL = trimURL(gameURL)
+	dialConfig := newDialConfig(gameURL, config.dialOptions)
+	var tls bool
+	if config.tls != nil {
+		tls = *config.tls
+	} else {
+		config.reportProgress(StageTLS, nil)
+		tls = probeTLS(gameURL, dialConfig)
+	}
+
+	socket := newSocket(gameURL, tls, config.gameID, config.playerID)
+	socket.dialConfig = dialConfig
+	socket.eventBufferSize = config.eventBufferSize
+	socket.eventChan = make(chan Event, config.eventBufferSize)
+	socket.strictDecoding = config.strictDecoding
+	socket.suppressEchoes = config.suppressEchoes
+	socket.dropRawData = config.dropRawData
+	socket.eventTTL = config.eventTTL
+	socket.SetBackpressure(config.highWatermark, config.lowWatermark)
+	for name, n := range config.sampling {
+		socket.SetSampling(name, n)
+	}
+	for name, window := range config.aggregation {
+		socket.SetAggregation(name, window)
+	}
+	socket.SetPrefetchUsernames(config.prefetch)
+	socket.journal = config.journal
+	socket.logger = config.logger
+	socket.reconnectPolicy = config.reconnect
+	socket.readTimeout = config.readTimeout
+	socket.writeTimeout = config.writeTimeout
+	socket.usernames = socket.newUsernameCache(config.usernameCache)
+	// Servers of older CodeGame versions wrap their events.
+	socket.protocol = detectProtocol(dialConfig.httpClient(), gameURL, tls)
+	if len(config.multiplexGames) > 0 {
+		socket.multiplexGames = config.multiplexGames
+		socket.protocol = multiplexProtocol{socket.protocol}
+	}
+
+	if config.checkEvents && config.logger != nil {
+		def, err := fetchEventsDefinition(dialConfig.httpClient(), gameURL, tls)
+		if err != nil {
+			socket.logf("cg: failed to fetch events definition: %s", err)
+		} else {
+			socket.eventsDefinition = def
+		}
+	}
+
+	config.reportProgress(StageWebsocket, nil)
+	var err error
+	if config.playerID == "" {
+		err = socket.spectate(config.gameID)
+	} else {
+		err = socket.connect(config.gameID, config.playerID, config.playerSecret)
+	}
+	if err != nil {
+		err = newConnectError(StageWebsocket, err)
+		config.reportProgress(err.(*ConnectError).Stage, err)
+		return nil, err
+	}
+
+	// The heartbeat is configured before the listen loop starts reading.
+	socket.heartbeatTimeout = int64(config.heartbeatTimeout)
+	socket.pingInterval = int64(config.pingInterval)
+	socket.startListenLoop()
+	socket.restartPinging()
+
+	if len(socket.multiplexGames) == 0 {
+		config.reportProgress(StagePlayers, nil)
+		err = socket.usernames.refresh()
+		if err != nil {
+			socket.Close()
+			err = newConnectError(StagePlayers, err)
+			config.reportProgress(StagePlayers, err)
+			return nil, err
+		}
+	}
+
+	config.reportProgress(StageDone, nil)
+	return socket, nil
+}
+
+// logf logs a message with all secrets redacted.
+func (s *Socket) logf(format string, v ...any) {
+	if s.logger != nil {
+		s.logger.Printf("%s", Redact(fmt.Sprintf(format, v...)))
+	}
+}
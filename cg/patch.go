@@ -0,0 +1,437 @@
+package cg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PatchFailedEvent is generated locally when a patch received with an event registered with
+// OnPatch or OnMergePatch cannot be applied. Its data is a PatchFailure.
+const PatchFailedEvent EventName = "cg_patch_failed"
+
+// PatchFailure is the data of PatchFailedEvent.
+type PatchFailure struct {
+	Event EventName `json:"event"`
+	Error string    `json:"error"`
+}
+
+// Document is a JSON document that is kept up to date by applying JSON Patches (RFC 6902) or JSON Merge Patches (RFC 7386).
+// Patches are applied atomically: if any operation fails, the document is left unchanged.
+type Document struct {
+	lock  sync.RWMutex
+	value any
+}
+
+// NewDocument creates a document with the initial JSON value.
+// An empty initial value results in an empty object.
+func NewDocument(initial json.RawMessage) (*Document, error) {
+	if len(bytes.TrimSpace(initial)) == 0 {
+		return &Document{value: map[string]any{}}, nil
+	}
+	value, err := decodeJSONValue(initial)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{value: value}, nil
+}
+
+// JSON returns the encoded document.
+func (d *Document) JSON() (json.RawMessage, error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return json.Marshal(d.value)
+}
+
+// Unmarshal decodes the document into the value pointed to by targetObjPtr.
+func (d *Document) Unmarshal(targetObjPtr any) error {
+	data, err := d.JSON()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, targetObjPtr)
+}
+
+// Set replaces the whole document.
+func (d *Document) Set(value json.RawMessage) error {
+	v, err := decodeJSONValue(value)
+	if err != nil {
+		return err
+	}
+	d.lock.Lock()
+	d.value = v
+	d.lock.Unlock()
+	return nil
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyPatch applies a JSON Patch (RFC 6902) to the document.
+func (d *Document) ApplyPatch(patch json.RawMessage) error {
+	var operations []patchOperation
+	err := json.Unmarshal(patch, &operations)
+	if err != nil {
+		return fmt.Errorf("invalid patch: %w", err)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	doc, err := copyJSONValue(d.value)
+	if err != nil {
+		return err
+	}
+	for i, op := range operations {
+		doc, err = applyOperation(doc, op)
+		if err != nil {
+			return fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	d.value = doc
+	return nil
+}
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) to the document.
+func (d *Document) ApplyMergePatch(patch json.RawMessage) error {
+	p, err := decodeJSONValue(patch)
+	if err != nil {
+		return fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	doc, err := copyJSONValue(d.value)
+	if err != nil {
+		return err
+	}
+	d.value = mergePatch(doc, p)
+	return nil
+}
+
+// OnPatch registers a listener that applies the data of event as a JSON Patch to doc.
+// If the patch cannot be applied, a PatchFailedEvent is dispatched.
+func (s *Socket) OnPatch(event EventName, doc *Document) CallbackID {
+	return s.On(event, func(e Event) {
+		patch, err := e.rawData()
+		if err == nil {
+			err = doc.ApplyPatch(patch)
+		}
+		s.reportPatchFailure(e.Name, err)
+	})
+}
+
+// OnMergePatch registers a listener that applies the data of event as a JSON Merge Patch to doc.
+// If the patch cannot be applied, a PatchFailedEvent is dispatched.
+func (s *Socket) OnMergePatch(event EventName, doc *Document) CallbackID {
+	return s.On(event, func(e Event) {
+		patch, err := e.rawData()
+		if err == nil {
+			err = doc.ApplyMergePatch(patch)
+		}
+		s.reportPatchFailure(e.Name, err)
+	})
+}
+
+func (s *Socket) reportPatchFailure(event EventName, err error) {
+	if err == nil {
+		return
+	}
+	data, err := json.Marshal(PatchFailure{Event: event, Error: err.Error()})
+	if err != nil {
+		return
+	}
+	s.triggerEventListeners(Event{Name: PatchFailedEvent, Data: data})
+}
+
+func decodeJSONValue(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+// jsonValuesEqual reports whether two values decoded by decodeJSONValue are equal as defined by RFC 6902 for the test operation.
+// Unlike reflect.DeepEqual it compares numbers by value, e.g. 1 and 1.0 are equal.
+func jsonValuesEqual(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okX := new(big.Rat).SetString(string(a))
+		y, okY := new(big.Rat).SetString(string(b))
+		return okX && okY && x.Cmp(y) == 0
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !jsonValuesEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonValuesEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		// Strings, booleans and null.
+		return a == b
+	}
+}
+
+func copyJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(data)
+}
+
+func mergePatch(target, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatch(targetObj[key], value)
+		}
+	}
+	return targetObj
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer '%s'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index '%s'", token)
+	}
+	max := length - 1
+	if allowEnd {
+		max = length
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+func getValue(doc any, tokens []string) (any, error) {
+	for _, t := range tokens {
+		switch v := doc.(type) {
+		case map[string]any:
+			child, ok := v[t]
+			if !ok {
+				return nil, fmt.Errorf("member '%s' does not exist", t)
+			}
+			doc = child
+		case []any:
+			index, err := arrayIndex(t, len(v), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = v[index]
+		default:
+			return nil, fmt.Errorf("cannot resolve '%s' in a primitive value", t)
+		}
+	}
+	return doc, nil
+}
+
+// modifyParent resolves the parent of the value referenced by tokens and replaces it with the result of fn.
+func modifyParent(doc any, tokens []string, fn func(parent any, last string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return fn(doc, tokens[0])
+	}
+	switch v := doc.(type) {
+	case map[string]any:
+		child, ok := v[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("member '%s' does not exist", tokens[0])
+		}
+		newChild, err := modifyParent(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		v[tokens[0]] = newChild
+		return v, nil
+	case []any:
+		index, err := arrayIndex(tokens[0], len(v), false)
+		if err != nil {
+			return nil, err
+		}
+		newChild, err := modifyParent(v[index], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = newChild
+		return v, nil
+	default:
+		return nil, fmt.Errorf("cannot resolve '%s' in a primitive value", tokens[0])
+	}
+}
+
+func addValue(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return modifyParent(doc, tokens, func(parent any, last string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			p[last] = value
+			return p, nil
+		case []any:
+			index, err := arrayIndex(last, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[index+1:], p[index:])
+			p[index] = value
+			return p, nil
+		default:
+			return nil, errors.New("cannot add a member to a primitive value")
+		}
+	})
+}
+
+func removeValue(doc any, tokens []string) (any, error) {
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	return modifyParent(doc, tokens, func(parent any, last string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			if _, ok := p[last]; !ok {
+				return nil, fmt.Errorf("member '%s' does not exist", last)
+			}
+			delete(p, last)
+			return p, nil
+		case []any:
+			index, err := arrayIndex(last, len(p), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:index], p[index+1:]...), nil
+		default:
+			return nil, errors.New("cannot remove a member from a primitive value")
+		}
+	})
+}
+
+func applyOperation(doc any, op patchOperation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, errors.New("missing value")
+		}
+		value, err := decodeJSONValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return addValue(doc, path, value)
+		case "replace":
+			if _, err = getValue(doc, path); err != nil {
+				return nil, err
+			}
+			if len(path) > 0 {
+				doc, err = removeValue(doc, path)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return addValue(doc, path, value)
+		default:
+			current, err := getValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonValuesEqual(current, value) {
+				return nil, errors.New("test failed")
+			}
+			return doc, nil
+		}
+	case "remove":
+		return removeValue(doc, path)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+				return nil, errors.New("cannot move a value into one of its children")
+			}
+			doc, err = removeValue(doc, from)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			value, err = copyJSONValue(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return addValue(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown operation '%s'", op.Op)
+	}
+}
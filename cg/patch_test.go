@@ -0,0 +1,105 @@
+package cg_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+		// fails is true if the patch must be rejected and the document left unchanged.
+		fails bool
+	}{
+		{name: "add member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, expected: `{"baz":"qux","foo":"bar"}`},
+		{name: "add array element", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, expected: `{"foo":["bar","qux","baz"]}`},
+		{name: "add to end of array", doc: `{"foo":[1,2]}`, patch: `[{"op":"add","path":"/foo/-","value":3}]`, expected: `{"foo":[1,2,3]}`},
+		{name: "add nested member", doc: `{"foo":{}}`, patch: `[{"op":"add","path":"/foo/bar","value":{"a":[1]}}]`, expected: `{"foo":{"bar":{"a":[1]}}}`},
+		{name: "add replaces whole document", doc: `{"foo":1}`, patch: `[{"op":"add","path":"","value":[1]}]`, expected: `[1]`},
+		{name: "add out of bounds", doc: `{"foo":[1]}`, patch: `[{"op":"add","path":"/foo/2","value":2}]`, fails: true},
+		{name: "add to missing parent", doc: `{}`, patch: `[{"op":"add","path":"/a/b","value":1}]`, fails: true},
+
+		{name: "remove member", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, expected: `{"foo":"bar"}`},
+		{name: "remove array element", doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, expected: `{"foo":["bar","baz"]}`},
+		{name: "remove missing member", doc: `{"foo":1}`, patch: `[{"op":"remove","path":"/bar"}]`, fails: true},
+		{name: "remove end of array", doc: `{"foo":[1]}`, patch: `[{"op":"remove","path":"/foo/-"}]`, fails: true},
+
+		{name: "replace member", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, expected: `{"baz":"boo","foo":"bar"}`},
+		{name: "replace array element", doc: `[1,2,3]`, patch: `[{"op":"replace","path":"/1","value":5}]`, expected: `[1,5,3]`},
+		{name: "replace missing member", doc: `{}`, patch: `[{"op":"replace","path":"/foo","value":1}]`, fails: true},
+
+		{name: "move member", doc: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, expected: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "move array element", doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, expected: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "move into child", doc: `{"a":{"b":{}}}`, patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`, fails: true},
+
+		{name: "copy member", doc: `{"a":{"b":[1]}}`, patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b/-","value":2}]`, expected: `{"a":{"b":[1]},"c":{"b":[1,2]}}`},
+		{name: "copy missing member", doc: `{}`, patch: `[{"op":"copy","from":"/a","path":"/b"}]`, fails: true},
+
+		{name: "test equal values", doc: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, expected: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{name: "test numbers by value", doc: `{"a":1,"b":[100]}`, patch: `[{"op":"test","path":"/a","value":1.0},{"op":"test","path":"/b","value":[1e2]}]`, expected: `{"a":1,"b":[100]}`},
+		{name: "test objects ignore member order", doc: `{"a":{"x":1,"y":null}}`, patch: `[{"op":"test","path":"/a","value":{"y":null,"x":1}}]`, expected: `{"a":{"x":1,"y":null}}`},
+		{name: "test different value", doc: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, fails: true},
+		{name: "test different number", doc: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":1.5}]`, fails: true},
+		{name: "test string and number", doc: `{"a":"1"}`, patch: `[{"op":"test","path":"/a","value":1}]`, fails: true},
+		{name: "failed test is atomic", doc: `{"a":1}`, patch: `[{"op":"add","path":"/b","value":2},{"op":"test","path":"/a","value":2}]`, fails: true},
+
+		{name: "escaped slash", doc: `{"a/b":1}`, patch: `[{"op":"replace","path":"/a~1b","value":2}]`, expected: `{"a/b":2}`},
+		{name: "escaped tilde", doc: `{"m~n":1}`, patch: `[{"op":"remove","path":"/m~0n"}]`, expected: `{}`},
+		{name: "escape order", doc: `{"~1":1}`, patch: `[{"op":"test","path":"/~01","value":1}]`, expected: `{"~1":1}`},
+		{name: "invalid pointer", doc: `{}`, patch: `[{"op":"add","path":"a","value":1}]`, fails: true},
+		{name: "leading zero index", doc: `[1,2]`, patch: `[{"op":"remove","path":"/01"}]`, fails: true},
+		{name: "unknown operation", doc: `{}`, patch: `[{"op":"frobnicate","path":"/a"}]`, fails: true},
+		{name: "missing value", doc: `{}`, patch: `[{"op":"add","path":"/a"}]`, fails: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := cg.NewDocument(json.RawMessage(test.doc))
+			if err != nil {
+				t.Fatalf("invalid document: %s", err)
+			}
+			err = doc.ApplyPatch(json.RawMessage(test.patch))
+			expected := test.expected
+			if test.fails {
+				if err == nil {
+					t.Fatal("expected the patch to fail")
+				}
+				expected = test.doc
+			} else if err != nil {
+				t.Fatalf("failed to apply patch: %s", err)
+			}
+			assertJSON(t, doc, expected)
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	doc, err := cg.NewDocument(json.RawMessage(`{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = doc.ApplyMergePatch(json.RawMessage(`{"title":"Hello!","author":{"familyName":null},"tags":["example"],"phoneNumber":"+01-123-456-7890"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSON(t, doc, `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"phoneNumber":"+01-123-456-7890"}`)
+}
+
+func assertJSON(t *testing.T, doc *cg.Document, expected string) {
+	t.Helper()
+	data, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actualValue, expectedValue any
+	json.Unmarshal(data, &actualValue)
+	json.Unmarshal([]byte(expected), &expectedValue)
+	if !reflect.DeepEqual(actualValue, expectedValue) {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+}
@@ -0,0 +1,53 @@
+package cg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// cacheDir returns the directory used for cached files and creates it if it does not exist yet.
+func cacheDir(elem ...string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(append([]string{dir, "codegame"}, elem...)...)
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// dataDir returns the directory used for persistent files and creates it if it does not exist yet.
+func dataDir(elem ...string) (string, error) {
+	dir, err := dataRoot()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(append([]string{dir}, elem...)...)
+	return dir, os.MkdirAll(dir, 0o755)
+}
+
+// dataRoot returns the root directory of all persistent files without creating it.
+// The data directory of the config file takes precedence. On Linux $XDG_DATA_HOME/codegame (default: ~/.local/share/codegame) is used, on other systems the user config directory.
+func dataRoot() (string, error) {
+	if dir := Config().DataDir; dir != "" {
+		return dir, nil
+	}
+	var dir string
+	if runtime.GOOS == "linux" {
+		dir = os.Getenv("XDG_DATA_HOME")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, ".local", "share")
+		}
+	} else {
+		var err error
+		dir, err = os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(dir, "codegame"), nil
+}
@@ -0,0 +1,40 @@
+package cg
+
+// playerEventData is the data of NewPlayerEvent and PlayerLeftEvent.
+type playerEventData struct {
+	PlayerID string `json:"player_id"`
+	Username string `json:"username"`
+}
+
+// decodePlayerEvent returns the player of a NewPlayerEvent or PlayerLeftEvent.
+// The player ID is taken from the data if present and from the origin of the event otherwise.
+func decodePlayerEvent(event Event) playerEventData {
+	var data playerEventData
+	raw, _ := event.rawData()
+	codec.Unmarshal(raw, &data)
+	if data.PlayerID == "" && event.Origin != OriginServer {
+		data.PlayerID = event.Origin
+	}
+	return data
+}
+
+// OnPlayerJoined registers a callback that is triggered when a player joins the game.
+// If the event does not contain the username, it is looked up in the username cache.
+// playerID is empty if the server provides neither a player ID nor origin metadata.
+func (s *Socket) OnPlayerJoined(callback func(playerID, username string)) CallbackID {
+	return s.On(NewPlayerEvent, func(event Event) {
+		data := decodePlayerEvent(event)
+		if data.Username == "" && data.PlayerID != "" {
+			data.Username = s.Username(data.PlayerID)
+		}
+		callback(data.PlayerID, data.Username)
+	})
+}
+
+// OnPlayerLeft registers a callback that is triggered when a player leaves the game.
+// playerID is empty if the server provides neither a player ID nor origin metadata.
+func (s *Socket) OnPlayerLeft(callback func(playerID string)) CallbackID {
+	return s.On(PlayerLeftEvent, func(event Event) {
+		callback(decodePlayerEvent(event).PlayerID)
+	})
+}
@@ -0,0 +1,100 @@
+package cg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorDim    = "\x1b[2m"
+	colorCyan   = "\x1b[1;36m"
+	colorYellow = "\x1b[1;33m"
+)
+
+// String returns the event formatted by FormatEvent without colors.
+func (e Event) String() string {
+	return FormatEvent(e, false)
+}
+
+// String returns the command formatted by FormatCommand without colors.
+func (c Command) String() string {
+	return FormatCommand(c, false)
+}
+
+// FormatEvent formats event as a header line with its name and origin followed by its indented data.
+// If color is true, the output contains ANSI escape codes.
+func FormatEvent(event Event, color bool) string {
+	var name strings.Builder
+	name.WriteString(string(event.Name))
+	if event.Origin != "" {
+		name.WriteString(" from " + event.Origin)
+	}
+	if event.Target != "" {
+		name.WriteString(" to " + event.Target)
+	}
+	data, _ := event.rawData()
+	return format("event", name.String(), colorCyan, data, color)
+}
+
+// FormatCommand formats cmd as a header line with its name followed by its indented data.
+// If color is true, the output contains ANSI escape codes.
+func FormatCommand(cmd Command, color bool) string {
+	return format("command", string(cmd.Name), colorYellow, cmd.Data, color)
+}
+
+func format(kind, name, nameColor string, data json.RawMessage, color bool) string {
+	var b strings.Builder
+	if color {
+		fmt.Fprintf(&b, "%s%-8s%s%s%s%s", colorDim, kind, colorReset, nameColor, name, colorReset)
+	} else {
+		fmt.Fprintf(&b, "%-8s%s", kind, name)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || string(trimmed) == "{}" || string(trimmed) == "null" {
+		return b.String()
+	}
+	var indented bytes.Buffer
+	err := json.Indent(&indented, trimmed, "  ", "  ")
+	if err != nil {
+		// Print invalid data as is.
+		indented.Reset()
+		indented.Write(trimmed)
+	}
+	b.WriteString("\n  ")
+	b.Write(indented.Bytes())
+	return b.String()
+}
+
+// DumpEvents registers a listener that writes every received event formatted by FormatEvent to w.
+// Colors are used according to the Color setting of Config. ColorAuto enables them if w is a terminal
+// and the NO_COLOR environment variable is not set.
+func (s *Socket) DumpEvents(w io.Writer) CallbackID {
+	color := useColor(Config().Color, w)
+	return s.OnAny(func(event Event) {
+		fmt.Fprintln(w, FormatEvent(event, color))
+	})
+}
+
+func useColor(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
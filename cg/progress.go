@@ -0,0 +1,57 @@
+package cg
+
+import "fmt"
+
+// ConnectStage is a step of establishing a connection with Dial.
+type ConnectStage int
+
+const (
+	// StageCredentials is the retrieval of the player secret from a CredentialsProvider.
+	StageCredentials ConnectStage = iota
+	// StageTLS is the check whether the server supports TLS.
+	StageTLS
+	// StageAuth is the retrieval of a bearer token from the TokenSource. It is only reported if it fails.
+	StageAuth
+	// StageWebsocket is the websocket handshake.
+	StageWebsocket
+	// StagePlayers is the initial fetch of the usernames of all players.
+	StagePlayers
+	// StageDone means the socket is ready to use.
+	StageDone
+)
+
+func (s ConnectStage) String() string {
+	switch s {
+	case StageCredentials:
+		return "Loading credentials"
+	case StageTLS:
+		return "Verifying TLS"
+	case StageAuth:
+		return "Authenticating"
+	case StageWebsocket:
+		return "Connecting"
+	case StagePlayers:
+		return "Fetching players"
+	case StageDone:
+		return "Connected"
+	default:
+		return fmt.Sprintf("ConnectStage(%d)", int(s))
+	}
+}
+
+// ProgressFunc is called with err == nil whenever Dial enters a new stage
+// and with the error if the stage fails.
+type ProgressFunc func(stage ConnectStage, err error)
+
+// WithProgress makes Dial report its progress to fn.
+func WithProgress(fn ProgressFunc) Option {
+	return func(config *socketConfig) {
+		config.progress = fn
+	}
+}
+
+func (c *socketConfig) reportProgress(stage ConnectStage, err error) {
+	if c.progress != nil {
+		c.progress(stage, err)
+	}
+}
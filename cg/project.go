@@ -0,0 +1,75 @@
+package cg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrNoProjectInfo = errors.New("no .codegame.json file found")
+
+// ProjectInfo contains the metadata stored in the .codegame.json file of a CodeGame project.
+type ProjectInfo struct {
+	Name       string         `json:"game"`
+	Type       string         `json:"type"`
+	Lang       string         `json:"lang"`
+	GameURL    string         `json:"game_url,omitempty"`
+	LangConfig map[string]any `json:"lang_config,omitempty"`
+}
+
+// LoadProjectInfo reads and validates the .codegame.json file at path.
+func LoadProjectInfo(path string) (ProjectInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ProjectInfo{}, err
+	}
+	defer file.Close()
+
+	var info ProjectInfo
+	err = json.NewDecoder(file).Decode(&info)
+	if err != nil {
+		return ProjectInfo{}, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	err = info.Validate()
+	if err != nil {
+		return ProjectInfo{}, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return info, nil
+}
+
+// FindProjectInfo searches the current working directory and its parents for a .codegame.json file and loads it.
+// FindProjectInfo returns ErrNoProjectInfo if no file was found.
+func FindProjectInfo() (ProjectInfo, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ProjectInfo{}, err
+	}
+	for {
+		path := filepath.Join(dir, ".codegame.json")
+		if _, err = os.Stat(path); err == nil {
+			return LoadProjectInfo(path)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ProjectInfo{}, ErrNoProjectInfo
+		}
+		dir = parent
+	}
+}
+
+// Validate verifies that all required fields are set.
+func (p ProjectInfo) Validate() error {
+	if p.Name == "" {
+		return errors.New("missing game name")
+	}
+	if p.Type != "client" && p.Type != "server" {
+		return fmt.Errorf("invalid project type '%s'", p.Type)
+	}
+	if p.Type == "client" && p.GameURL == "" {
+		return errors.New("missing game URL")
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package cg
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// protocol decodes the event messages of a specific generation of the CodeGame protocol.
+type protocol interface {
+	decodeEvent(msg []byte, event *Event) error
+}
+
+// flatProtocol is the current protocol, which sends events as `{"name": …, "data": …}`.
+type flatProtocol struct{}
+
+func (flatProtocol) decodeEvent(msg []byte, event *Event) error {
+	return codec.Unmarshal(msg, event)
+}
+
+// wrapperProtocol is the protocol of CodeGame versions up to wrapperProtocolMaxVersion,
+// which wraps every event as `{"origin": …, "target": …, "event": {"name": …, "data": …}}`.
+type wrapperProtocol struct{}
+
+type eventWrapper struct {
+	Origin string          `json:"origin"`
+	Target json.RawMessage `json:"target"`
+	Event  Event           `json:"event"`
+}
+
+// multiplexProtocol decodes the events of a multiplexed connection, which carry the ID of their game in `game_id`.
+type multiplexProtocol struct {
+	protocol
+}
+
+func (p multiplexProtocol) decodeEvent(msg []byte, event *Event) error {
+	var game struct {
+		GameID string `json:"game_id"`
+	}
+	err := codec.Unmarshal(msg, &game)
+	if err != nil {
+		return err
+	}
+	err = p.protocol.decodeEvent(msg, event)
+	event.gameID = game.GameID
+	return err
+}
+
+func (wrapperProtocol) decodeEvent(msg []byte, event *Event) error {
+	var wrapper eventWrapper
+	err := codec.Unmarshal(msg, &wrapper)
+	if err != nil {
+		return err
+	}
+	if wrapper.Event.Name == "" {
+		// Unwrapped events are accepted as well.
+		return codec.Unmarshal(msg, event)
+	}
+	*event = wrapper.Event
+	event.Origin = wrapper.Origin
+	event.Target = wrapperTarget(wrapper.Target)
+	return nil
+}
+
+// wrapperTarget extracts the target player ID, which is either a string or an object with an id field.
+func wrapperTarget(raw json.RawMessage) string {
+	var target string
+	if json.Unmarshal(raw, &target) == nil {
+		return target
+	}
+	var object struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(raw, &object)
+	return object.ID
+}
+
+// wrapperProtocolMaxVersion is the last CodeGame version that used wrapped events.
+const wrapperProtocolMaxVersion = "0.6"
+
+// protocolForVersion selects the protocol of a server advertising cgVersion. Patch versions are ignored.
+// Unknown versions use the current protocol.
+func protocolForVersion(cgVersion string) protocol {
+	parts := strings.SplitN(cgVersion, ".", 3)
+	if len(parts) > 2 {
+		cgVersion = parts[0] + "." + parts[1]
+	}
+	if cgVersion != "" && compareVersions(cgVersion, wrapperProtocolMaxVersion) <= 0 {
+		return wrapperProtocol{}
+	}
+	return flatProtocol{}
+}
+
+// detectProtocol selects the protocol of the server at trimmedURL using the version advertised by /api/info.
+// The info is cached in the REST cache shared by all sockets on the server.
+func detectProtocol(client *http.Client, trimmedURL string, tls bool) protocol {
+	info, err := sharedRESTCache(trimmedURL).get("info", func() (any, error) {
+		return fetchInfo(client, trimmedURL, tls)
+	})
+	if err != nil {
+		return flatProtocol{}
+	}
+	return protocolForVersion(info.(ServerInfo).CGVersion)
+}
+
+// compareVersions compares two versions like "0.7" or "v1.2.3" numerically
+// and returns -1, 0 or 1. Missing components are treated as 0.
+func compareVersions(a, b string) int {
+	partsA := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
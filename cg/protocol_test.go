@@ -0,0 +1,115 @@
+package cg
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"0.6", "0.6", 0},
+		{"0.6", "0.7", -1},
+		{"0.10", "0.9", 1},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.1", "1.2", 1},
+	}
+	for _, test := range tests {
+		if result := compareVersions(test.a, test.b); result != test.expected {
+			t.Errorf("compareVersions(%q, %q): expected %d, got %d", test.a, test.b, test.expected, result)
+		}
+	}
+}
+
+func TestProtocolForVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected protocol
+	}{
+		{"0.5", wrapperProtocol{}},
+		{"0.6", wrapperProtocol{}},
+		{"0.6.9", wrapperProtocol{}},
+		{"0.7", flatProtocol{}},
+		{"0.10", flatProtocol{}},
+		{"", flatProtocol{}},
+	}
+	for _, test := range tests {
+		if p := protocolForVersion(test.version); p != test.expected {
+			t.Errorf("version %q: expected %T, got %T", test.version, test.expected, p)
+		}
+	}
+}
+
+func TestWrapperProtocolDecodeEvent(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    string
+		origin string
+		target string
+	}{
+		{name: "string target", msg: `{"origin":"server","target":"p1","event":{"name":"move","data":1}}`, origin: "server", target: "p1"},
+		{name: "object target", msg: `{"origin":"server","target":{"id":"p2"},"event":{"name":"move","data":1}}`, origin: "server", target: "p2"},
+		{name: "unwrapped", msg: `{"name":"move","data":1}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var event Event
+			err := wrapperProtocol{}.decodeEvent([]byte(test.msg), &event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event.Name != "move" || string(event.Data) != "1" {
+				t.Errorf("expected event move with data 1, got %s with %s", event.Name, event.Data)
+			}
+			if event.Origin != test.origin || event.Target != test.target {
+				t.Errorf("expected origin %q and target %q, got %q and %q", test.origin, test.target, event.Origin, event.Target)
+			}
+		})
+	}
+}
+
+func TestDialDetectsWrapperProtocol(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/info":
+			json.NewEncoder(w).Encode(ServerInfo{Name: "old", CGVersion: "0.6"})
+		case "/api/games/g/players":
+			w.Write([]byte("{}"))
+		case "/api/games/g/spectate":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"origin":"server","target":"p1","event":{"name":"move","data":{"x":1}}}`))
+			conn.ReadMessage()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	socket, err := Dial(server.URL, WithGame("g"), WithTLS(false))
+	if err != nil {
+		t.Fatalf("failed to connect: %s", err)
+	}
+	defer socket.Close()
+
+	select {
+	case event := <-socket.EventChan():
+		if event.Name != "move" || event.Origin != "server" || event.Target != "p1" {
+			t.Errorf("expected the wrapped move event from server to p1, got %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the wrapped event")
+	}
+}
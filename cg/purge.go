@@ -0,0 +1,49 @@
+package cg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// localDataPaths are the files and directories in the data directory that identify the user.
+// "games" contains the session files of earlier versions of the CodeGame tools.
+var localDataPaths = []string{"sessions", "games", usernamesFile}
+
+// PurgeLocalData removes all sessions stored with Session.Save, the remembered usernames and the session files
+// of earlier versions of the CodeGame tools, e.g. to wipe credentials from a shared machine.
+// It returns the removed files. If dryRun is true, nothing is removed and the files that would be removed are returned.
+func PurgeLocalData(dryRun bool) ([]string, error) {
+	root, err := dataRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, name := range localDataPaths {
+		path := filepath.Join(root, name)
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return files, err
+		}
+		if !dryRun {
+			err = os.RemoveAll(path)
+			if err != nil {
+				return files, err
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,48 @@
+package cg
+
+// QueueLen returns the number of received events that have not been processed yet.
+func (s *Socket) QueueLen() int {
+	eventChan, _ := s.channels()
+	return len(eventChan)
+}
+
+// Expired returns the number of received events that were discarded without triggering listeners
+// because they exceeded the TTL set with SetEventTTL. It equals Stats().ExpiredEvents.
+// Events are never discarded because the queue is full: the listen goroutine waits for free space
+// after triggering the OnOverflow callbacks instead. See also SetBackpressure.
+func (s *Socket) Expired() int {
+	return s.stats.expired()
+}
+
+// OnOverflow registers a callback that is triggered when the event queue is full or reaches the high watermark
+// set with SetBackpressure. The callback is invoked from the listen goroutine before it blocks or pauses reading,
+// so it must not wait for queued events to be processed.
+func (s *Socket) OnOverflow(callback func(queueLen, capacity int)) CallbackID {
+	s.overflowLock.Lock()
+	defer s.overflowLock.Unlock()
+	id := s.newCallbackID()
+	s.overflowCbs[id] = callback
+	return id
+}
+
+func (s *Socket) triggerOverflow(queueLen, capacity int) {
+	s.overflowLock.Lock()
+	callbacks := make([]func(queueLen, capacity int), 0, len(s.overflowCbs))
+	for _, cb := range s.overflowCbs {
+		callbacks = append(callbacks, cb)
+	}
+	s.overflowLock.Unlock()
+	for _, cb := range callbacks {
+		cb(queueLen, capacity)
+	}
+}
+
+// enqueue adds event to eventChan and triggers the overflow callbacks if it has to wait for free space.
+func (s *Socket) enqueue(eventChan chan Event, event Event) {
+	select {
+	case eventChan <- event:
+	default:
+		s.triggerOverflow(len(eventChan), cap(eventChan))
+		eventChan <- event
+	}
+}
@@ -0,0 +1,60 @@
+package cg_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestExpiredEvents(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice", cg.WithEventTTL(10*time.Millisecond))
+	var triggered int32
+	socket.On("tick", func(cg.Event) {
+		atomic.AddInt32(&triggered, 1)
+	})
+
+	server.Emit("tick", nil)
+	waitFor(t, "queued event", func() bool {
+		return socket.QueueLen() == 1
+	})
+	time.Sleep(30 * time.Millisecond)
+	cgtest.ExpectEvent(t, socket, "tick", time.Second)
+
+	if n := atomic.LoadInt32(&triggered); n != 0 {
+		t.Errorf("expected the expired event not to trigger listeners, got %d calls", n)
+	}
+	if n := socket.Expired(); n != 1 {
+		t.Errorf("expected 1 expired event, got %d", n)
+	}
+}
+
+func TestOverflowKeepsEvents(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice", cg.WithEventBufferSize(2))
+	var overflows int32
+	socket.OnOverflow(func(queueLen, capacity int) {
+		atomic.AddInt32(&overflows, 1)
+	})
+
+	for i := 0; i < 5; i++ {
+		server.Emit("tick", i)
+	}
+	waitFor(t, "overflow", func() bool {
+		return atomic.LoadInt32(&overflows) > 0
+	})
+	for i := 0; i < 5; i++ {
+		event := cgtest.ExpectEvent(t, socket, "tick", time.Second)
+		var n int
+		event.UnmarshalData(&n)
+		if n != i {
+			t.Fatalf("expected event %d, got %d", i, n)
+		}
+	}
+	if n := socket.Expired(); n != 0 {
+		t.Errorf("expected no expired events, got %d", n)
+	}
+}
@@ -0,0 +1,49 @@
+package cg
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+var readyBackoff = ExponentialBackoff{Initial: 100 * time.Millisecond, Max: 5 * time.Second}
+
+// WaitForServer polls the /api/info endpoint of the server with exponential backoff until it responds or ctx expires.
+// opts configure the connection to the server, e.g. with WithPinnedPublicKeys.
+func WaitForServer(ctx context.Context, gameURL string, opts ...DialOption) error {
+	gameURL = trimURL(gameURL)
+	config := newDialConfig(gameURL, opts)
+	for attempt := 1; ; attempt++ {
+		if serverReady(ctx, gameURL, config) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-clock.After(readyBackoff.NextDelay(attempt)):
+		}
+	}
+}
+
+func serverReady(ctx context.Context, trimmedURL string, config dialConfig) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL("http", probeTLS(trimmedURL, config), "%s/api/info", trimmedURL), nil)
+	if err != nil {
+		return false
+	}
+	resp, err := config.httpClient().Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// ConnectWhenReady waits until the server is ready (see WaitForServer) and connects to the game.
+func ConnectWhenReady(ctx context.Context, gameURL, gameID, playerID, playerSecret string, opts ...DialOption) (*Socket, error) {
+	err := WaitForServer(ctx, gameURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return Connect(gameURL, gameID, playerID, playerSecret, opts...)
+}
@@ -0,0 +1,102 @@
+package cg
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Reconnect closes the current websocket connection and connects again using the stored credentials.
+// All registered callbacks and the username cache are preserved.
+// OnClose and OnDisconnect callbacks are not triggered by closing the old connection.
+// If the new connection cannot be established, the socket is closed and OnDisconnect callbacks are triggered.
+func (s *Socket) Reconnect() error {
+	wsConn, err := s.redial()
+	if err != nil {
+		s.stateLock.Lock()
+		old := s.wsConn
+		if s.running {
+			// The listen loop ends the socket with err when the old connection is closed.
+			s.running = false
+			s.err = err
+		}
+		s.stateLock.Unlock()
+		closeConnection(old)
+		return err
+	}
+
+	s.stateLock.Lock()
+	old := s.wsConn
+	s.wsConn = wsConn
+	s.reconnected = true
+	if !s.running {
+		// The previous listen loop has already ended and closed the event channel.
+		s.eventChan = make(chan Event, s.eventBufferSize)
+		s.done = make(chan struct{})
+	}
+	// The new listen loop replaces the old one before the old connection is closed.
+	s.startListenLoopLocked()
+	s.stateLock.Unlock()
+	closeConnection(old)
+
+	s.restartPinging()
+	return nil
+}
+
+func closeConnection(conn wsConnection) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(5*time.Second))
+	conn.Close()
+}
+
+// redial establishes a new connection with the stored credentials. It does not replace s.wsConn.
+func (s *Socket) redial() (wsConnection, error) {
+	if s.playerID == "" {
+		return s.dialSpectator(s.gameID)
+	}
+	return s.dialPlayer(s.gameID, s.playerID, s.playerSecret)
+}
+
+// autoReconnect tries to restore a lost connection according to s.reconnectPolicy.
+// It is called from the listen loop of generation and returns true if a new listen loop has taken over.
+func (s *Socket) autoReconnect(generation int, reason error) bool {
+	policy := s.reconnectPolicy
+	backoff := policy.backoff()
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		s.logf("cg: connection lost (%s), reconnecting (attempt %d/%d)", reason, attempt, policy.MaxAttempts)
+		clock.Sleep(backoff.NextDelay(attempt))
+		if !s.isCurrent(generation) {
+			// Reconnect has been called in the meantime.
+			return true
+		}
+		if !s.isRunning() {
+			return false
+		}
+
+		s.connection().Close()
+		wsConn, err := s.redial()
+		if err == nil {
+			s.stateLock.Lock()
+			if s.generation != generation {
+				// Reconnect has replaced the connection while redialing.
+				s.stateLock.Unlock()
+				wsConn.Close()
+				return true
+			}
+			if !s.running {
+				// The socket has been closed while redialing.
+				s.stateLock.Unlock()
+				wsConn.Close()
+				return false
+			}
+			s.wsConn = wsConn
+			s.reconnected = true
+			s.startListenLoopLocked()
+			s.stateLock.Unlock()
+			s.restartPinging()
+			s.logf("cg: reconnected")
+			return true
+		}
+		s.logf("cg: reconnect failed: %s", err)
+	}
+	return false
+}
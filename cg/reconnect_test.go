@@ -0,0 +1,129 @@
+package cg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func dialPlayer(t *testing.T, server *cgtest.Server, username string, opts ...cg.Option) *cg.Socket {
+	t.Helper()
+	playerID, secret := server.AddPlayer(username)
+	opts = append([]cg.Option{cg.WithGame(server.GameID), cg.WithPlayer(playerID, secret), cg.WithTLS(false)}, opts...)
+	socket, err := cg.Dial(server.URL, opts...)
+	if err != nil {
+		t.Fatalf("failed to connect to mock server: %s", err)
+	}
+	t.Cleanup(func() {
+		socket.Close()
+	})
+	return socket
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+
+	err := socket.Reconnect()
+	if err != nil {
+		t.Fatalf("Reconnect failed: %s", err)
+	}
+	server.Emit("hello", nil)
+	cgtest.ExpectEvent(t, socket, "hello", time.Second)
+	if err := socket.Err(); err != nil {
+		t.Fatalf("expected the socket to be connected, got %v", err)
+	}
+}
+
+func TestSendDuringReconnect(t *testing.T) {
+	server := cgtest.NewServer(t)
+	// Pings are written with a deadline of one interval, which must not expire under the race detector.
+	socket := dialPlayer(t, server, "alice", cg.WithPingInterval(20*time.Millisecond))
+
+	stop := make(chan struct{})
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				// Commands sent to the replaced connection may fail.
+				socket.Send("move", nil)
+			}
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		err := socket.Reconnect()
+		if err != nil {
+			t.Fatalf("Reconnect failed: %s", err)
+		}
+	}
+	close(stop)
+	<-sent
+
+	err := socket.Send("done", nil)
+	if err != nil {
+		t.Fatalf("Send failed after reconnecting: %s", err)
+	}
+	cgtest.ExpectCommand(t, server, "done", nil)
+}
+
+func TestReconnectFailureWithFullQueue(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice", cg.WithEventBufferSize(1))
+	disconnected := make(chan error, 1)
+	socket.OnDisconnect(func(err error) {
+		disconnected <- err
+	})
+
+	// The listen loop blocks on the full queue.
+	for i := 0; i < 3; i++ {
+		server.Emit("tick", i)
+	}
+	waitFor(t, "full queue", func() bool {
+		return socket.QueueLen() == 1
+	})
+	time.Sleep(20 * time.Millisecond)
+
+	// Redialing fails because the server is gone.
+	server.Close()
+	reconnectErr := socket.Reconnect()
+	if reconnectErr == nil {
+		t.Fatal("expected Reconnect to fail")
+	}
+
+	events := 0
+	socket.OnAny(func(cg.Event) {
+		events++
+	})
+	err := socket.RunEventLoop()
+	if err != reconnectErr {
+		t.Fatalf("expected RunEventLoop to return %v, got %v", reconnectErr, err)
+	}
+	if events == 0 {
+		t.Error("expected the queued events to be delivered")
+	}
+	select {
+	case err := <-disconnected:
+		if err != reconnectErr {
+			t.Errorf("expected OnDisconnect with %v, got %v", reconnectErr, err)
+		}
+	default:
+		t.Error("expected OnDisconnect to be triggered")
+	}
+}
@@ -0,0 +1,36 @@
+package cg
+
+// eventTypes maps event names to decoders for their registered data types.
+var eventTypes = make(map[EventName]func(event *Event) (any, error))
+
+// RegisterEventType makes sockets decode the data of every received event called name into a value of type T.
+// The decoded value is available through Event.DecodedData, which avoids decoding the same event in every listener.
+// RegisterEventType should be called before any socket is created, e.g. in an init function.
+func RegisterEventType[T any](name EventName) {
+	eventTypes[name] = func(event *Event) (any, error) {
+		var data T
+		err := event.UnmarshalData(&data)
+		return data, err
+	}
+}
+
+// DecodedData returns the data of the event decoded into the type registered with RegisterEventType.
+// It returns nil if no type is registered for the event or decoding failed. Use UnmarshalData to get the error.
+func (e Event) DecodedData() any {
+	return e.decoded
+}
+
+// DecodedDataAs returns the decoded data of event if it has the type T.
+func DecodedDataAs[T any](event Event) (T, bool) {
+	data, ok := event.decoded.(T)
+	return data, ok
+}
+
+func (e *Event) decode() {
+	if decode, ok := eventTypes[e.Name]; ok {
+		data, err := decode(e)
+		if err == nil {
+			e.decoded = data
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package cg
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Relay forwards the events of a socket to local websocket clients (e.g. a browser visualizer)
+// and sends the commands of those clients through the socket.
+// Clients speak the same protocol as a CodeGame server.
+type Relay struct {
+	socket *Socket
+	// CheckOrigin decides whether a client is allowed to connect.
+	// By default only same-origin requests are accepted.
+	CheckOrigin func(r *http.Request) bool
+
+	lock    sync.Mutex
+	clients map[chan []byte]struct{}
+}
+
+// NewRelay creates a relay for socket.
+// Events are only forwarded while the event loop of socket is running.
+func NewRelay(socket *Socket) *Relay {
+	r := &Relay{
+		socket:  socket,
+		clients: make(map[chan []byte]struct{}),
+	}
+	socket.OnAny(r.broadcast)
+	return r
+}
+
+func (r *Relay) broadcast(event Event) {
+	raw, err := event.rawData()
+	if err != nil {
+		return
+	}
+	event.Data = raw
+	data, err := codec.Marshal(event)
+	if err != nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for ch := range r.clients {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+// ServeHTTP upgrades the request to a websocket connection and relays events and commands until the client disconnects.
+// Commands of clients connected to a relay for a spectator socket are ignored.
+func (r *Relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: r.CheckOrigin}
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ch := make(chan []byte, 64)
+	r.lock.Lock()
+	r.clients[ch] = struct{}{}
+	r.lock.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			msgType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType != websocket.TextMessage || r.socket.IsSpectating() {
+				continue
+			}
+			var cmd Command
+			err = codec.Unmarshal(msg, &cmd)
+			if err != nil || cmd.Name == "" {
+				continue
+			}
+			r.socket.Send(cmd.Name, cmd.Data)
+		}
+	}()
+
+	defer func() {
+		r.lock.Lock()
+		delete(r.clients, ch)
+		r.lock.Unlock()
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		case data := <-ch:
+			err = conn.WriteMessage(websocket.TextMessage, data)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
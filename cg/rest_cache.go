@@ -0,0 +1,96 @@
+package cg
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// restCacheTTL is the time a REST lookup result stays cached. 0 disables caching.
+var restCacheTTL = 10 * time.Second
+
+// SetRESTCacheTTL sets how long player and game metadata fetched from the REST API are cached.
+// The cache is shared by all sockets connected to the same server. Concurrent lookups of the same resource
+// are always deduplicated, even if ttl is 0, which disables caching. Defaults to 10 seconds.
+func SetRESTCacheTTL(ttl time.Duration) {
+	restCaches.lock.Lock()
+	restCacheTTL = ttl
+	for _, c := range restCaches.servers {
+		c.clear()
+	}
+	restCaches.lock.Unlock()
+}
+
+var restCaches = struct {
+	lock    sync.Mutex
+	servers map[string]*restCache
+}{servers: make(map[string]*restCache)}
+
+type restCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// restCache caches the results of REST lookups on a single server.
+type restCache struct {
+	lock    sync.Mutex
+	entries map[string]restCacheEntry
+	flights flightGroup[any]
+}
+
+// sharedRESTCache returns the cache of the server at trimmedURL.
+func sharedRESTCache(trimmedURL string) *restCache {
+	restCaches.lock.Lock()
+	defer restCaches.lock.Unlock()
+	c, ok := restCaches.servers[trimmedURL]
+	if !ok {
+		c = &restCache{entries: make(map[string]restCacheEntry)}
+		restCaches.servers[trimmedURL] = c
+	}
+	return c
+}
+
+// get returns the cached value of key or calls fetch once for all concurrent callers and caches the result.
+// Errors are not cached.
+func (c *restCache) get(key string, fetch func() (any, error)) (any, error) {
+	now := clock.Now()
+	c.lock.Lock()
+	entry, ok := c.entries[key]
+	if ok && now.Before(entry.expires) {
+		c.lock.Unlock()
+		return entry.value, nil
+	}
+	delete(c.entries, key)
+	c.lock.Unlock()
+
+	return c.flights.do(key, func() (any, error) {
+		value, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		restCaches.lock.Lock()
+		ttl := restCacheTTL
+		restCaches.lock.Unlock()
+		if ttl > 0 {
+			c.lock.Lock()
+			c.entries[key] = restCacheEntry{value: value, expires: clock.Now().Add(ttl)}
+			c.lock.Unlock()
+		}
+		return value, nil
+	})
+}
+
+// invalidate removes all entries whose key starts with prefix.
+func (c *restCache) invalidate(prefix string) {
+	c.lock.Lock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+	c.lock.Unlock()
+}
+
+func (c *restCache) clear() {
+	c.invalidate("")
+}
@@ -0,0 +1,47 @@
+package cg
+
+import "sync/atomic"
+
+// RosterChangeKind is the kind of a RosterEvent.
+type RosterChangeKind int
+
+const (
+	RosterJoin RosterChangeKind = iota
+	RosterLeave
+	RosterRename
+)
+
+// rosterBufferSize is the capacity of the channel returned by RosterChanges.
+const rosterBufferSize = 100
+
+// RosterEvent describes a change of the players in the game.
+type RosterEvent struct {
+	Kind     RosterChangeKind
+	PlayerID string
+	// Username is the new username for RosterRename and the last known username for RosterLeave.
+	Username string
+	// OldUsername is only set for RosterRename.
+	OldUsername string
+}
+
+// RosterChanges returns a channel that receives the changes of the players in the game.
+// The player list is refreshed whenever a NewPlayerEvent or PlayerLeftEvent is received.
+// Players that are already in the game are reported as joins first.
+// Changes are dropped while the channel is full and counted in Stats.DroppedRosterChanges, so it should be drained.
+// The channel is never closed; use Done to detect the end of the connection.
+func (s *Socket) RosterChanges() <-chan RosterEvent {
+	s.rosterOnce.Do(func() {
+		s.roster = make(chan RosterEvent, rosterBufferSize)
+		s.usernames.watchRoster(func(event RosterEvent) {
+			select {
+			case s.roster <- event:
+			default:
+				// Refreshes must not block, e.g. while Username waits for one.
+				s.stats.rosterDrop()
+			}
+		})
+		atomic.StoreInt32(&s.watchingRoster, 1)
+		go s.usernames.refresh()
+	})
+	return s.roster
+}
@@ -0,0 +1,38 @@
+package cg_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestRosterChangesDoNotBlockUsername(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+	changes := socket.RosterChanges()
+
+	// More players than the channel can hold while nobody drains it.
+	for i := 0; i < 150; i++ {
+		server.AddPlayer(fmt.Sprintf("player %d", i))
+	}
+	server.Emit(cg.NewPlayerEvent, map[string]string{"username": "player 149"})
+	waitFor(t, "full roster channel", func() bool {
+		return len(changes) == cap(changes)
+	})
+	done := make(chan string, 1)
+	go func() {
+		done <- socket.Username("unknown")
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Username blocked on the undrained roster channel")
+	}
+
+	if dropped := socket.Stats().DroppedRosterChanges; dropped == 0 {
+		t.Error("expected dropped roster changes to be counted")
+	}
+}
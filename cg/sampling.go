@@ -0,0 +1,174 @@
+package cg
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AggregateEvent is generated locally for events aggregated with SetAggregation. Its data is Aggregate.
+const AggregateEvent EventName = "cg_aggregate"
+
+// Aggregate is the data of AggregateEvent.
+type Aggregate struct {
+	// Name is the name of the aggregated events.
+	Name  EventName `json:"name"`
+	Count int       `json:"count"`
+	// First and Last are the receive times of the first and the last aggregated event.
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+	// Data is the data of the last aggregated event.
+	Data json.RawMessage `json:"data"`
+}
+
+// SetSampling makes the socket only dispatch every nth event with the specified name, starting with the first one.
+// The other events are still recorded in the journal. Passing n <= 1 disables sampling of the event.
+// This reduces the processing cost of high-frequency events, e.g. for analytics spectators.
+func (s *Socket) SetSampling(name EventName, n int) {
+	s.shaper.lock.Lock()
+	defer s.shaper.lock.Unlock()
+	if n <= 1 {
+		delete(s.shaper.samplers, name)
+		return
+	}
+	if s.shaper.samplers == nil {
+		s.shaper.samplers = make(map[EventName]*sampler)
+	}
+	s.shaper.samplers[name] = &sampler{every: n}
+}
+
+// SetAggregation makes the socket collect the events with the specified name over time windows of the specified length
+// and dispatch a single AggregateEvent per window instead.
+// An aggregate is dispatched with the first event received after its window has elapsed
+// and when the connection ends. Passing window <= 0 disables aggregation of the event.
+func (s *Socket) SetAggregation(name EventName, window time.Duration) {
+	s.shaper.lock.Lock()
+	defer s.shaper.lock.Unlock()
+	if window <= 0 {
+		delete(s.shaper.windows, name)
+		return
+	}
+	if s.shaper.windows == nil {
+		s.shaper.windows = make(map[EventName]*aggregationWindow)
+	}
+	s.shaper.windows[name] = &aggregationWindow{length: window}
+}
+
+// WithSampling is equivalent to calling SetSampling after connecting.
+func WithSampling(name EventName, n int) Option {
+	return func(config *socketConfig) {
+		if config.sampling == nil {
+			config.sampling = make(map[EventName]int)
+		}
+		config.sampling[name] = n
+	}
+}
+
+// WithAggregation is equivalent to calling SetAggregation after connecting.
+func WithAggregation(name EventName, window time.Duration) Option {
+	return func(config *socketConfig) {
+		if config.aggregation == nil {
+			config.aggregation = make(map[EventName]time.Duration)
+		}
+		config.aggregation[name] = window
+	}
+}
+
+// eventShaper implements sampling and aggregation of received events.
+type eventShaper struct {
+	lock     sync.Mutex
+	samplers map[EventName]*sampler
+	windows  map[EventName]*aggregationWindow
+}
+
+type sampler struct {
+	every int
+	seen  int
+}
+
+type aggregationWindow struct {
+	length    time.Duration
+	aggregate *Aggregate
+}
+
+// settings returns the sampling and aggregation configuration.
+func (e *eventShaper) settings() (map[EventName]int, map[EventName]time.Duration) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	sampling := make(map[EventName]int, len(e.samplers))
+	for name, s := range e.samplers {
+		sampling[name] = s.every
+	}
+	aggregation := make(map[EventName]time.Duration, len(e.windows))
+	for name, w := range e.windows {
+		aggregation[name] = w.length
+	}
+	return sampling, aggregation
+}
+
+// shape returns the aggregates whose window has elapsed and whether event should be dispatched.
+func (e *eventShaper) shape(event Event) ([]Event, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if len(e.samplers) == 0 && len(e.windows) == 0 {
+		return nil, true
+	}
+
+	now := clock.Now()
+	due := e.takeAggregates(func(w *aggregationWindow) bool {
+		return now.Sub(w.aggregate.First) >= w.length
+	})
+
+	if w, ok := e.windows[event.Name]; ok {
+		if w.aggregate == nil {
+			w.aggregate = &Aggregate{Name: event.Name, First: now}
+		}
+		w.aggregate.Count++
+		w.aggregate.Last = now
+		w.aggregate.Data = event.Data
+		return due, false
+	}
+	if s, ok := e.samplers[event.Name]; ok {
+		s.seen++
+		return due, (s.seen-1)%s.every == 0
+	}
+	return due, true
+}
+
+// flush returns all pending aggregates.
+func (e *eventShaper) flush() []Event {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.takeAggregates(func(*aggregationWindow) bool {
+		return true
+	})
+}
+
+// takeAggregates removes the pending aggregates of the windows selected by due
+// and returns them as events ordered by the start of their windows.
+func (e *eventShaper) takeAggregates(due func(w *aggregationWindow) bool) []Event {
+	var aggregates []*Aggregate
+	for _, w := range e.windows {
+		if w.aggregate != nil && due(w) {
+			aggregates = append(aggregates, w.aggregate)
+			w.aggregate = nil
+		}
+	}
+	if len(aggregates) == 0 {
+		return nil
+	}
+	sort.Slice(aggregates, func(i, j int) bool {
+		return aggregates[i].First.Before(aggregates[j].First)
+	})
+
+	events := make([]Event, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		data, err := json.Marshal(aggregate)
+		if err != nil {
+			continue
+		}
+		events = append(events, Event{Name: AggregateEvent, Data: data})
+	}
+	return events
+}
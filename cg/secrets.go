@@ -0,0 +1,67 @@
+package cg
+
+import (
+	neturl "net/url"
+	"regexp"
+)
+
+const redacted = "REDACTED"
+
+// secretPattern matches secrets in query strings, JSON objects and Authorization headers.
+// The first group of every alternative is kept, the rest is replaced.
+var secretPattern = regexp.MustCompile(`((?:player_secret|join_secret|access_token)=)[^&\s"']*` +
+	`|("(?:player_secret|join_secret)"\s*:\s*")[^"]*` +
+	`|((?i:bearer) )[^\s"']+`)
+
+// Redact replaces player secrets, join secrets and access tokens in text, e.g. a URL or error message, with REDACTED.
+// Use it before logging data that may contain credentials.
+func Redact(text string) string {
+	return secretPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := secretPattern.FindStringSubmatch(match)
+		for _, prefix := range groups[1:] {
+			if prefix != "" {
+				return prefix + redacted
+			}
+		}
+		return redacted
+	})
+}
+
+// redactedError hides secrets in the message of an error.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError returns err with all secrets removed from its message.
+// The URL of a *url.Error is redacted in a copy so that the error keeps its type.
+func redactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if urlErr, ok := err.(*neturl.Error); ok {
+		redactedErr := *urlErr
+		redactedErr.URL = Redact(urlErr.URL)
+		return &redactedErr
+	}
+	msg := err.Error()
+	if r := Redact(msg); r != msg {
+		return &redactedError{err: err, msg: r}
+	}
+	return err
+}
+
+// wipe overwrites b with zeros.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,67 @@
+package cg_test
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"query", "ws://host/api/games/g/players/p/connect?player_secret=abc123&trace=true", "ws://host/api/games/g/players/p/connect?player_secret=REDACTED&trace=true"},
+		{"join secret", "join_secret=xyz failed", "join_secret=REDACTED failed"},
+		{"access token", "wss://host/api/spectate?access_token=tok", "wss://host/api/spectate?access_token=REDACTED"},
+		{"json", `{"player_id":"p","player_secret": "abc123"}`, `{"player_id":"p","player_secret": "REDACTED"}`},
+		{"authorization", "Authorization: Bearer tok.en-1", "Authorization: Bearer REDACTED"},
+		{"no secret", "player p joined game g", "player p joined game g"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if redacted := cg.Redact(test.text); redacted != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, redacted)
+			}
+		})
+	}
+}
+
+func TestSessionStringHidesSecret(t *testing.T) {
+	session := cg.Session{GameURL: "example.com", GameID: "g", PlayerID: "p", PlayerSecret: "abc123"}
+	for _, text := range []string{session.String(), fmt.Sprint(session), fmt.Sprintf("%v", &session)} {
+		if strings.Contains(text, "abc123") {
+			t.Errorf("expected the secret to be redacted, got %s", text)
+		}
+	}
+}
+
+func TestLeaveErrorHidesSecret(t *testing.T) {
+	server := cgtest.NewServer(t)
+	playerID, secret := server.AddPlayer("alice")
+	socket, err := cg.Dial(server.URL, cg.WithGame(server.GameID), cg.WithPlayer(playerID, secret), cg.WithTLS(false))
+	if err != nil {
+		t.Fatalf("failed to connect to mock server: %s", err)
+	}
+	defer socket.Close()
+
+	// Deleting the player fails with an error containing the request URL.
+	server.Close()
+	err = socket.Leave()
+	if err == nil {
+		t.Fatal("expected Leave to fail")
+	}
+	if strings.Contains(err.Error(), secret) {
+		t.Errorf("expected the player secret to be redacted, got %s", err)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || !strings.Contains(urlErr.URL, "player_secret=REDACTED") {
+		t.Errorf("expected a *url.Error with a redacted URL, got %#v", err)
+	}
+}
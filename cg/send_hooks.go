@@ -0,0 +1,79 @@
+package cg
+
+// BeforeSendHook is called by Send with the command before it is encoded.
+// The hook may modify the command. Returning an error vetoes the command and Send returns the error.
+type BeforeSendHook func(cmd *Command) error
+
+// AfterSendHook is called by Send with the command, its encoded message and the result of the write.
+// It is not called for commands that were vetoed by a BeforeSendHook.
+type AfterSendHook func(cmd Command, msg []byte, err error)
+
+type sendHook struct {
+	id     CallbackID
+	before BeforeSendHook
+	after  AfterSendHook
+}
+
+// BeforeSend registers a hook that is called for every command sent with Send.
+// Hooks are called in the order of registration and the first error stops the command.
+// It can be removed with RemoveCallback.
+func (s *Socket) BeforeSend(hook BeforeSendHook) CallbackID {
+	return s.addSendHook(sendHook{before: hook})
+}
+
+// AfterSend registers a hook that is called after a command has been written to the connection or failed to be written.
+// It can be removed with RemoveCallback.
+func (s *Socket) AfterSend(hook AfterSendHook) CallbackID {
+	return s.addSendHook(sendHook{after: hook})
+}
+
+func (s *Socket) addSendHook(hook sendHook) CallbackID {
+	s.sendHooksLock.Lock()
+	defer s.sendHooksLock.Unlock()
+	hook.id = s.newCallbackID()
+	// Copy on write so that Send can iterate over a snapshot without holding the lock.
+	hooks := make([]sendHook, len(s.sendHooks), len(s.sendHooks)+1)
+	copy(hooks, s.sendHooks)
+	s.sendHooks = append(hooks, hook)
+	return hook.id
+}
+
+func (s *Socket) removeSendHook(id CallbackID) {
+	s.sendHooksLock.Lock()
+	defer s.sendHooksLock.Unlock()
+	for i, hook := range s.sendHooks {
+		if hook.id == id {
+			hooks := make([]sendHook, 0, len(s.sendHooks)-1)
+			hooks = append(hooks, s.sendHooks[:i]...)
+			s.sendHooks = append(hooks, s.sendHooks[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Socket) currentSendHooks() []sendHook {
+	s.sendHooksLock.RLock()
+	defer s.sendHooksLock.RUnlock()
+	return s.sendHooks
+}
+
+func runBeforeSend(hooks []sendHook, cmd *Command) error {
+	for _, hook := range hooks {
+		if hook.before == nil {
+			continue
+		}
+		err := hook.before(cmd)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runAfterSend(hooks []sendHook, cmd Command, msg []byte, err error) {
+	for _, hook := range hooks {
+		if hook.after != nil {
+			hook.after(cmd, msg, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package cg
+
+import "encoding/json"
+
+// SequenceGap is the data of SequenceGapEvent.
+type SequenceGap struct {
+	// Expected is the sequence number that should have been received next.
+	Expected uint64 `json:"expected"`
+	// Received is the sequence number that was actually received.
+	Received uint64 `json:"received"`
+	// Reconnected is true if the gap occurred across a reconnect.
+	Reconnected bool `json:"reconnected"`
+}
+
+// Missed returns the number of events that were not received.
+func (g SequenceGap) Missed() uint64 {
+	return g.Received - g.Expected
+}
+
+// checkSequence records the sequence number of event and returns a SequenceGapEvent if events were skipped.
+// Sequence numbers lower than the last one are treated as a restart of the numbering.
+func (s *Socket) checkSequence(event Event) (Event, bool) {
+	if event.Sequence == 0 {
+		return Event{}, false
+	}
+	last := s.lastSequence
+	s.lastSequence = event.Sequence
+	s.stateLock.Lock()
+	reconnected := s.reconnected
+	s.reconnected = false
+	s.stateLock.Unlock()
+	if last == 0 || event.Sequence <= last+1 {
+		return Event{}, false
+	}
+
+	data, err := json.Marshal(SequenceGap{
+		Expected:    last + 1,
+		Received:    event.Sequence,
+		Reconnected: reconnected,
+	})
+	if err != nil {
+		return Event{}, false
+	}
+	return Event{Name: SequenceGapEvent, Data: data}, true
+}
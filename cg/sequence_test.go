@@ -0,0 +1,79 @@
+package cg_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/gorilla/websocket"
+)
+
+// newSequenceServer starts a server streaming numbered events to spectators of game "g".
+// Every connection skips a sequence number, so that each reconnect causes a gap.
+func newSequenceServer(t *testing.T) *httptest.Server {
+	var sequence uint64
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/games/g/players":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{}"))
+		case "/api/games/g/spectate":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			atomic.AddUint64(&sequence, 1)
+			for {
+				msg, _ := json.Marshal(cg.Event{Name: "tick", Sequence: atomic.AddUint64(&sequence, 1)})
+				if conn.WriteMessage(websocket.TextMessage, msg) != nil {
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSequenceGapAcrossReconnect(t *testing.T) {
+	server := newSequenceServer(t)
+	socket, err := cg.Dial(server.URL, cg.WithGame("g"), cg.WithTLS(false))
+	if err != nil {
+		t.Fatalf("failed to connect: %s", err)
+	}
+	var reconnectGaps int32
+	socket.On(cg.SequenceGapEvent, func(event cg.Event) {
+		var gap cg.SequenceGap
+		if event.UnmarshalData(&gap) == nil && gap.Reconnected {
+			atomic.AddInt32(&reconnectGaps, 1)
+		}
+	})
+	loopDone := make(chan struct{})
+	go func() {
+		socket.RunEventLoop()
+		close(loopDone)
+	}()
+
+	for i := 0; i < 5; i++ {
+		socket.SetPrefetchUsernames(i%2 == 0)
+		err := socket.Reconnect()
+		if err != nil {
+			t.Fatalf("Reconnect failed: %s", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	waitFor(t, "gap after reconnect", func() bool {
+		return atomic.LoadInt32(&reconnectGaps) > 0
+	})
+	socket.Close()
+	<-loopDone
+}
@@ -0,0 +1,177 @@
+package cg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrInvalidPassphrase = errors.New("invalid passphrase")
+
+// Session contains everything required to connect to a game as a specific player.
+// PlayerID and PlayerSecret are empty for spectator sessions.
+type Session struct {
+	GameURL      string `json:"game_url"`
+	GameID       string `json:"game_id"`
+	PlayerID     string `json:"player_id,omitempty"`
+	PlayerSecret string `json:"player_secret,omitempty"`
+}
+
+// String returns a description of the session with the player secret redacted, so that sessions can be logged safely.
+func (s Session) String() string {
+	secret := ""
+	if s.PlayerSecret != "" {
+		secret = redacted
+	}
+	return fmt.Sprintf("{GameURL:%s GameID:%s PlayerID:%s PlayerSecret:%s}", s.GameURL, s.GameID, s.PlayerID, secret)
+}
+
+// IsSpectator reports whether the session belongs to a spectator.
+func (s Session) IsSpectator() bool {
+	return s.PlayerID == ""
+}
+
+// Connect connects to the game using the session.
+// Spectator sessions start spectating the game instead.
+func (s Session) Connect() (*Socket, error) {
+	if s.IsSpectator() {
+		return Dial(s.GameURL, WithGame(s.GameID))
+	}
+	return Connect(s.GameURL, s.GameID, s.PlayerID, s.PlayerSecret)
+}
+
+// Session returns the session of the socket.
+func (s *Socket) Session() Session {
+	return Session{
+		GameURL:      s.gameURL,
+		GameID:       s.gameID,
+		PlayerID:     s.playerID,
+		PlayerSecret: s.playerSecret,
+	}
+}
+
+const (
+	sessionBundleVersion = 1
+	sessionKDFIterations = 100000
+)
+
+type sessionBundle struct {
+	Version   int    `json:"version"`
+	Encrypted bool   `json:"encrypted"`
+	Salt      []byte `json:"salt,omitempty"`
+	Nonce     []byte `json:"nonce,omitempty"`
+	Data      []byte `json:"data"`
+}
+
+// ExportSession writes session to w as a portable bundle.
+// If passphrase is not empty, the bundle is encrypted with AES-GCM using a key derived from passphrase.
+func ExportSession(w io.Writer, session Session, passphrase string) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return ErrEncodeFailed
+	}
+	defer wipe(data)
+
+	bundle := sessionBundle{
+		Version: sessionBundleVersion,
+		Data:    data,
+	}
+
+	if passphrase != "" {
+		bundle.Encrypted = true
+		bundle.Salt = make([]byte, 16)
+		_, err = rand.Read(bundle.Salt)
+		if err != nil {
+			return err
+		}
+
+		aead, err := sessionCipher(passphrase, bundle.Salt)
+		if err != nil {
+			return err
+		}
+
+		bundle.Nonce = make([]byte, aead.NonceSize())
+		_, err = rand.Read(bundle.Nonce)
+		if err != nil {
+			return err
+		}
+		bundle.Data = aead.Seal(nil, bundle.Nonce, data, nil)
+	}
+
+	return json.NewEncoder(w).Encode(bundle)
+}
+
+// ImportSession reads a bundle created by ExportSession from r.
+// passphrase is ignored if the bundle is not encrypted.
+func ImportSession(r io.Reader, passphrase string) (Session, error) {
+	var bundle sessionBundle
+	err := json.NewDecoder(r).Decode(&bundle)
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to decode session bundle: %w", err)
+	}
+	if bundle.Version != sessionBundleVersion {
+		return Session{}, fmt.Errorf("unsupported session bundle version: %d", bundle.Version)
+	}
+
+	data := bundle.Data
+	if bundle.Encrypted {
+		aead, err := sessionCipher(passphrase, bundle.Salt)
+		if err != nil {
+			return Session{}, err
+		}
+		if len(bundle.Nonce) != aead.NonceSize() {
+			return Session{}, errors.New("invalid session bundle nonce")
+		}
+		data, err = aead.Open(nil, bundle.Nonce, bundle.Data, nil)
+		if err != nil {
+			return Session{}, ErrInvalidPassphrase
+		}
+	}
+	defer wipe(data)
+
+	var session Session
+	err = json.Unmarshal(data, &session)
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return session, nil
+}
+
+func sessionCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	password := []byte(passphrase)
+	defer wipe(password)
+	key := pbkdf2SHA256(password, salt, sessionKDFIterations)
+	defer wipe(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a 32 byte key from password as described in RFC 8018.
+func pbkdf2SHA256(password, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, password)
+	prf.Write(salt)
+	prf.Write([]byte{0, 0, 0, 1})
+	u := prf.Sum(nil)
+
+	key := make([]byte, len(u))
+	copy(key, u)
+	for i := 1; i < iterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	wipe(u)
+	return key
+}
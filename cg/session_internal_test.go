@@ -0,0 +1,29 @@
+package cg
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// The vectors are the first 32 bytes of the PBKDF2-HMAC-SHA256 outputs of RFC 7914, section 11,
+// and the SHA-256 equivalents of the RFC 6070 vectors.
+func TestPBKDF2SHA256(t *testing.T) {
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		expected   string
+	}{
+		{password: "password", salt: "salt", iterations: 1, expected: "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{password: "password", salt: "salt", iterations: 2, expected: "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{password: "password", salt: "salt", iterations: 4096, expected: "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+		{password: "passwd", salt: "salt", iterations: 1, expected: "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc"},
+		{password: "Password", salt: "NaCl", iterations: 80000, expected: "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56"},
+	}
+	for _, test := range tests {
+		key := pbkdf2SHA256([]byte(test.password), []byte(test.salt), test.iterations)
+		if actual := hex.EncodeToString(key); actual != test.expected {
+			t.Errorf("%s/%s/%d: expected %s, got %s", test.password, test.salt, test.iterations, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cg
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+var ErrNoSession = errors.New("session not found")
+
+// sessionPath returns the path of the file storing the session of playerID in the game at gameURL.
+func sessionPath(gameURL, playerID string) (string, error) {
+	dir, err := dataDir("sessions", url.PathEscape(trimURL(gameURL)))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, url.PathEscape(playerID)+".json"), nil
+}
+
+// Save stores the session on disk so that it can be loaded later with LoadSession.
+// Spectator sessions cannot be saved.
+func (s Session) Save() error {
+	if s.IsSpectator() {
+		return errors.New("cannot save a spectator session")
+	}
+	path, err := sessionPath(s.GameURL, s.PlayerID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return ErrEncodeFailed
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadSession loads a session stored with Session.Save.
+// LoadSession returns ErrNoSession if there is no such session.
+func LoadSession(gameURL, playerID string) (Session, error) {
+	path, err := sessionPath(gameURL, playerID)
+	if err != nil {
+		return Session{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Session{}, ErrNoSession
+		}
+		return Session{}, err
+	}
+	var session Session
+	err = json.Unmarshal(data, &session)
+	if err != nil {
+		return Session{}, ErrDecodeFailed
+	}
+	return session, nil
+}
+
+// DeleteSession removes a session stored with Session.Save.
+// Deleting a session that does not exist is not an error.
+func DeleteSession(gameURL, playerID string) error {
+	path, err := sessionPath(gameURL, playerID)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cg_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+func TestSessionBundle(t *testing.T) {
+	session := cg.Session{
+		GameURL:      "games.example.com",
+		GameID:       "game",
+		PlayerID:     "player",
+		PlayerSecret: "top-secret",
+	}
+
+	for _, passphrase := range []string{"", "correct horse battery staple"} {
+		var buf bytes.Buffer
+		err := cg.ExportSession(&buf, session, passphrase)
+		if err != nil {
+			t.Fatalf("failed to export session: %s", err)
+		}
+
+		imported, err := cg.ImportSession(bytes.NewReader(buf.Bytes()), passphrase)
+		if err != nil {
+			t.Fatalf("passphrase %q: failed to import session: %s", passphrase, err)
+		}
+		if imported != session {
+			t.Errorf("passphrase %q: expected %#v, got %#v", passphrase, session, imported)
+		}
+	}
+}
+
+func TestSessionBundleWrongPassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	err := cg.ExportSession(&buf, cg.Session{GameURL: "games.example.com", GameID: "game"}, "right")
+	if err != nil {
+		t.Fatalf("failed to export session: %s", err)
+	}
+	for _, passphrase := range []string{"wrong", ""} {
+		_, err = cg.ImportSession(bytes.NewReader(buf.Bytes()), passphrase)
+		if err != cg.ErrInvalidPassphrase {
+			t.Errorf("passphrase %q: expected %v, got %v", passphrase, cg.ErrInvalidPassphrase, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cg
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// shutdownTimeout is the time RunUntilInterrupt waits for the server to acknowledge the close frame.
+const shutdownTimeout = 5 * time.Second
+
+// Shutdown gracefully closes the connection.
+// It stops pinging, waits for commands that are currently being sent, sends a close frame
+// and waits until the server acknowledges it and the listen loop has ended or ctx is done.
+// The connection is closed in either case. Events received before the acknowledgement are still delivered.
+// Shutdown returns ctx.Err() if ctx is done before the server acknowledged the close frame.
+func (s *Socket) Shutdown(ctx context.Context) error {
+	s.stateLock.Lock()
+	s.running = false
+	done := s.done
+	wsConn := s.wsConn
+	s.stateLock.Unlock()
+
+	s.writeLock.Lock()
+	err := wsConn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	s.writeLock.Unlock()
+	if err != nil {
+		return wsConn.Close()
+	}
+
+	select {
+	case <-done:
+		wsConn.Close()
+		return nil
+	case <-ctx.Done():
+		wsConn.Close()
+		return ctx.Err()
+	}
+}
+
+// RunUntilInterrupt runs the event loop of socket until the connection ends or the process receives SIGINT or SIGTERM,
+// in which case the socket is shut down gracefully with Shutdown.
+// It returns nil if the connection was closed normally or because of a signal.
+func RunUntilInterrupt(socket *Socket) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// shutdown receives the result of Shutdown or nil if no signal was received.
+	shutdown := make(chan error, 1)
+	signaled := false
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-socket.Done():
+			shutdown <- nil
+			return
+		}
+		signaled = true
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		shutdown <- socket.Shutdown(shutdownCtx)
+	}()
+
+	err := socket.RunEventLoop()
+	shutdownErr := <-shutdown
+	if signaled {
+		return shutdownErr
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package cg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+)
+
+// SimulatorScript describes a practice server as plain data.
+// Timeline events are emitted one after another once Simulator.Run is called
+// and responses are emitted whenever a matching command is received.
+//
+// Example script:
+//
+//	{
+//	  "name": "practice",
+//	  "timeline": [
+//	    {"after": "1s", "name": "start", "data": {}}
+//	  ],
+//	  "responses": [
+//	    {"command": "ping", "reply": [{"name": "pong", "after": "100ms", "to": "sender"}]}
+//	  ]
+//	}
+type SimulatorScript struct {
+	Name      string             `json:"name"`
+	Timeline  []ScriptedEvent    `json:"timeline"`
+	Responses []ScriptedResponse `json:"responses"`
+}
+
+// ScriptedEvent is an event emitted by a simulator script.
+type ScriptedEvent struct {
+	// After is the delay relative to the previous event of the same list, e.g. "1.5s".
+	After ScriptDuration  `json:"after,omitempty"`
+	Name  EventName       `json:"name"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	// To restricts the event to a player ID. Responses are sent to the sender of the command if To is "sender".
+	To string `json:"to,omitempty"`
+}
+
+// ScriptedResponse emits Reply whenever Command is received.
+// If Data is set, only commands with equal data match. The first matching entry of a script is used.
+type ScriptedResponse struct {
+	Command CommandName     `json:"command"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Reply   []ScriptedEvent `json:"reply"`
+}
+
+// ScriptDuration is a time.Duration that is encoded as a string like "1m30s" in JSON.
+type ScriptDuration time.Duration
+
+func (d ScriptDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *ScriptDuration) UnmarshalJSON(data []byte) error {
+	var text string
+	err := json.Unmarshal(data, &text)
+	if err != nil {
+		return fmt.Errorf("invalid duration %s: expected a string like \"1.5s\"", string(data))
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = ScriptDuration(duration)
+	return nil
+}
+
+// LoadSimulatorScript reads a simulator script from a JSON file.
+func LoadSimulatorScript(path string) (*SimulatorScript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var script SimulatorScript
+	err = json.Unmarshal(data, &script)
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulator script %s: %w", path, err)
+	}
+	return &script, nil
+}
+
+// Run registers the responses of script as handlers and emits its timeline in the background.
+// Handlers registered before for the same commands are replaced.
+func (sim *Simulator) Run(script *SimulatorScript) {
+	responses := make(map[CommandName][]ScriptedResponse)
+	for _, r := range script.Responses {
+		responses[r.Command] = append(responses[r.Command], r)
+	}
+	for name, candidates := range responses {
+		candidates := candidates
+		sim.Handle(name, func(ctx *SimulatorContext, cmd Command) {
+			for _, r := range candidates {
+				if r.Data != nil && !rawJSONEqual(r.Data, cmd.Data) {
+					continue
+				}
+				go sim.emitScripted(r.Reply, ctx.PlayerID)
+				return
+			}
+		})
+	}
+
+	go sim.emitScripted(script.Timeline, "")
+}
+
+// emitScripted emits events in order, waiting for the delay of each event relative to the previous one.
+func (sim *Simulator) emitScripted(events []ScriptedEvent, sender string) {
+	for _, event := range events {
+		if event.After > 0 {
+			clock.Sleep(time.Duration(event.After))
+		}
+		data := event.Data
+		if data == nil {
+			data = json.RawMessage("{}")
+		}
+		switch event.To {
+		case "":
+			sim.Emit(event.Name, data)
+		case "sender":
+			sim.EmitTo(sender, event.Name, data)
+		default:
+			sim.EmitTo(event.To, event.Name, data)
+		}
+	}
+}
+
+// rawJSONEqual reports whether a and b represent the same JSON value.
+func rawJSONEqual(a, b json.RawMessage) bool {
+	valueA, errA := decodeJSONValue(a)
+	valueB, errB := decodeJSONValue(b)
+	return errA == nil && errB == nil && reflect.DeepEqual(valueA, valueB)
+}
@@ -0,0 +1,272 @@
+package cg
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// SimulatorHandler is called for every command of type name sent by a simulated player.
+type SimulatorHandler func(ctx *SimulatorContext, cmd Command)
+
+// Simulator is a scriptable fake server for developing and fuzz-testing bots offline.
+// Handlers map the commands sent by simulated players to emitted events.
+// Sockets connected to a simulator behave like real sockets, but methods
+// that use the REST API directly, like Leave or FetchGameConfig, fail.
+type Simulator struct {
+	GameID string
+
+	lock     sync.Mutex
+	rand     *rand.Rand
+	handlers map[CommandName]SimulatorHandler
+	players  map[string]string
+	conns    map[*simConn]struct{}
+	next     int
+}
+
+// NewSimulator creates a simulator whose randomness is derived from seed, which makes simulations reproducible.
+func NewSimulator(seed int64) *Simulator {
+	return &Simulator{
+		GameID:   "simulation",
+		rand:     rand.New(rand.NewSource(seed)),
+		handlers: make(map[CommandName]SimulatorHandler),
+		players:  make(map[string]string),
+		conns:    make(map[*simConn]struct{}),
+	}
+}
+
+// Handle registers handler for the command called name. Commands without a handler are ignored.
+func (sim *Simulator) Handle(name CommandName, handler SimulatorHandler) {
+	sim.lock.Lock()
+	sim.handlers[name] = handler
+	sim.lock.Unlock()
+}
+
+// Connect creates a new player and returns a socket connected to it.
+// NewPlayerEvent is emitted to all other sockets.
+func (sim *Simulator) Connect(username string) *Socket {
+	sim.lock.Lock()
+	sim.next++
+	playerID := "player_" + strconv.Itoa(sim.next)
+	sim.players[playerID] = username
+	sim.lock.Unlock()
+
+	sim.emit(Event{Name: NewPlayerEvent, Origin: playerID}, map[string]string{"username": username})
+	return sim.attach(playerID)
+}
+
+// Spectate returns a socket connected as a spectator.
+func (sim *Simulator) Spectate() *Socket {
+	return sim.attach("")
+}
+
+func (sim *Simulator) attach(playerID string) *Socket {
+	conn := &simConn{sim: sim, playerID: playerID}
+	conn.cond = sync.NewCond(&conn.lock)
+	sim.lock.Lock()
+	sim.conns[conn] = struct{}{}
+	sim.lock.Unlock()
+
+	socket := newSocket("simulator", false, sim.GameID, playerID)
+	socket.wsConn = conn
+	socket.usernames = newUsernameCache(defaultUsernameCacheSize, sim.usernames, sim.username)
+	socket.startListenLoop()
+	socket.usernames.refresh()
+	return socket
+}
+
+// Emit sends an event with origin OriginServer to all connected sockets.
+func (sim *Simulator) Emit(name EventName, data any) error {
+	return sim.emit(Event{Name: name, Origin: OriginServer}, data)
+}
+
+// EmitTo sends an event with origin OriginServer to all sockets of playerID.
+func (sim *Simulator) EmitTo(playerID string, name EventName, data any) error {
+	return sim.emit(Event{Name: name, Origin: OriginServer, Target: playerID}, data)
+}
+
+// emit sends event with data to all sockets of event.Target or all sockets if the target is empty.
+func (sim *Simulator) emit(event Event, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return ErrEncodeFailed
+	}
+	event.Data = encoded
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return ErrEncodeFailed
+	}
+
+	sim.lock.Lock()
+	conns := make([]*simConn, 0, len(sim.conns))
+	for c := range sim.conns {
+		if event.Target == "" || c.playerID == event.Target {
+			conns = append(conns, c)
+		}
+	}
+	sim.lock.Unlock()
+
+	for _, c := range conns {
+		c.push(msg)
+	}
+	return nil
+}
+
+func (sim *Simulator) handleCommand(playerID string, data []byte) {
+	var cmd Command
+	if json.Unmarshal(data, &cmd) != nil {
+		return
+	}
+	sim.lock.Lock()
+	handler, ok := sim.handlers[cmd.Name]
+	sim.lock.Unlock()
+	if ok {
+		handler(&SimulatorContext{sim: sim, PlayerID: playerID}, cmd)
+	}
+}
+
+func (sim *Simulator) usernames() (map[string]string, error) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	players := make(map[string]string, len(sim.players))
+	for id, username := range sim.players {
+		players[id] = username
+	}
+	return players, nil
+}
+
+func (sim *Simulator) username(playerID string) (string, error) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+	return sim.players[playerID], nil
+}
+
+// SimulatorContext is passed to simulator handlers.
+type SimulatorContext struct {
+	sim *Simulator
+	// PlayerID is the player who sent the command.
+	PlayerID string
+}
+
+// Reply sends an event to the sockets of the player who sent the command. Its origin is the player.
+func (ctx *SimulatorContext) Reply(name EventName, data any) error {
+	return ctx.sim.emit(Event{Name: name, Origin: ctx.PlayerID, Target: ctx.PlayerID}, data)
+}
+
+// Emit sends an event to all connected sockets. Its origin is the player who sent the command.
+func (ctx *SimulatorContext) Emit(name EventName, data any) {
+	ctx.sim.emit(Event{Name: name, Origin: ctx.PlayerID}, data)
+}
+
+// EmitAfter sends an event to all connected sockets after delay. Its origin is the player who sent the command.
+func (ctx *SimulatorContext) EmitAfter(delay time.Duration, name EventName, data any) {
+	go func() {
+		<-clock.After(delay)
+		ctx.Emit(name, data)
+	}()
+}
+
+// Intn returns a random number in [0,n) from the seeded source of the simulator.
+func (ctx *SimulatorContext) Intn(n int) int {
+	ctx.sim.lock.Lock()
+	defer ctx.sim.lock.Unlock()
+	return ctx.sim.rand.Intn(n)
+}
+
+// Float64 returns a random number in [0.0,1.0) from the seeded source of the simulator.
+func (ctx *SimulatorContext) Float64() float64 {
+	ctx.sim.lock.Lock()
+	defer ctx.sim.lock.Unlock()
+	return ctx.sim.rand.Float64()
+}
+
+// simConn implements wsConnection in memory.
+type simConn struct {
+	sim      *Simulator
+	playerID string
+
+	lock     sync.Mutex
+	cond     *sync.Cond
+	queue    [][]byte
+	closeErr error
+}
+
+func (c *simConn) push(msg []byte) {
+	c.lock.Lock()
+	if c.closeErr == nil {
+		c.queue = append(c.queue, msg)
+	}
+	c.lock.Unlock()
+	c.cond.Signal()
+}
+
+func (c *simConn) ReadMessage() (int, []byte, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.queue) == 0 && c.closeErr == nil {
+		c.cond.Wait()
+	}
+	if len(c.queue) == 0 {
+		return 0, nil, c.closeErr
+	}
+	msg := c.queue[0]
+	c.queue = c.queue[1:]
+	return websocket.TextMessage, msg, nil
+}
+
+func (c *simConn) NextReader() (int, io.Reader, error) {
+	messageType, data, err := c.ReadMessage()
+	if err != nil {
+		return 0, nil, err
+	}
+	return messageType, bytes.NewReader(data), nil
+}
+
+func (c *simConn) WriteMessage(messageType int, data []byte) error {
+	switch {
+	case messageType == websocket.TextMessage && c.playerID != "":
+		c.sim.handleCommand(c.playerID, data)
+	case messageType == websocket.CloseMessage:
+		c.Close()
+	}
+	return nil
+}
+
+func (c *simConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	if messageType == websocket.CloseMessage {
+		c.Close()
+	}
+	return nil
+}
+
+// SetReadDeadline is a no-op because simulated connections cannot time out.
+func (c *simConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline is a no-op because writing to a simulated connection never blocks.
+func (c *simConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *simConn) SetPongHandler(h func(appData string) error) {}
+
+func (c *simConn) Close() error {
+	c.sim.lock.Lock()
+	delete(c.sim.conns, c)
+	c.sim.lock.Unlock()
+
+	c.lock.Lock()
+	if c.closeErr == nil {
+		c.closeErr = &websocket.CloseError{Code: websocket.CloseNormalClosure}
+	}
+	c.lock.Unlock()
+	c.cond.Broadcast()
+	return nil
+}
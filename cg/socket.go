@@ -1,8 +1,13 @@
 package cg
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -15,143 +20,400 @@ var (
 	ErrClosed             = errors.New("connection closed")
 )
 
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
 // Socket represents the connection with a CodeGame server and handles events.
 type Socket struct {
-	gameURL        string
-	tls            bool
-	wsConn         *websocket.Conn
-	eventListeners map[EventName]map[CallbackID]EventCallback
-	usernameCache  map[string]string
+	gameURL    string
+	tls        bool
+	dialConfig dialConfig
+	// wsConn is guarded by stateLock once the listen loop has started, because it is replaced when reconnecting.
+	wsConn wsConnection
+	// writeLock serializes writes to wsConn because Send may be called from multiple goroutines.
+	writeLock sync.Mutex
 
-	gameID   string
-	playerID string
+	eventListeners map[EventName]map[CallbackID]EventCallback
+	// dispatchCache contains the listeners of eventListeners sorted by CallbackID.
+	// Entries are invalidated whenever the listeners of an event change.
+	dispatchCache map[EventName][]listener
+	// lifecycleLock guards disconnectCbs and closeCbs, which are read by the listen goroutine.
+	lifecycleLock sync.Mutex
+	disconnectCbs map[CallbackID]func(err error)
+	closeCbs      map[CallbackID]func()
+	overflowLock  sync.Mutex
+	overflowCbs   map[CallbackID]func(queueLen, capacity int)
+	usernames     *usernameCache
+
+	gameID       string
+	playerID     string
+	playerSecret string
+	// multiplexGames are the games spectated over the connection if it is multiplexed. See SpectateMultiplexed.
+	multiplexGames []string
+
+	// stateLock guards wsConn, running, generation, err, eventChan and done,
+	// which are shared by the listen goroutine and the goroutines using the socket.
+	stateLock  sync.Mutex
+	running    bool
+	generation int
+	// epoch is incremented atomically whenever a listen loop starts. See Epoch.
+	epoch    int32
+	eventTTL time.Duration
+	// highWatermark and lowWatermark are accessed atomically. See SetBackpressure.
+	highWatermark int32
+	lowWatermark  int32
+	eventChan     chan Event
+	// done is closed together with eventChan.
+	done chan struct{}
+	err  error
+
+	stats   *statsCollector
+	timer   *callbackTimer
+	journal Journal
+	shaper  eventShaper
+
+	sendHooksLock sync.RWMutex
+	sendHooks     []sendHook
+
+	lastSequence uint64
+	// clientSequence is the ClientSequence of the last received event.
+	clientSequence uint64
+	// reconnected is guarded by stateLock. It is reset by checkSequence.
+	reconnected bool
+
+	// heartbeatTimeout and pingInterval are durations and pinging is 1 while the ping loop is running.
+	// They are accessed atomically.
+	heartbeatTimeout int64
+	pingInterval     int64
+	pinging          int32
+	pings            pingTracker
+	// readTimeout and writeTimeout are disabled if 0.
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+
+	// rtt and clockOffset are the durations measured by the last MeasureLatency call.
+	// They are accessed atomically.
+	rtt         int64
+	clockOffset int64
+
+	// protocol decodes received events. See Dial.
+	protocol       protocol
+	strictDecoding bool
+	suppressEchoes bool
+	dropRawData    bool
+	// prefetchUsernames is 1 if enabled. It is accessed atomically.
+	prefetchUsernames int32
+	playerWaiters     int32
+	// roster is created by RosterChanges. watchingRoster is 1 afterwards.
+	roster         chan RosterEvent
+	rosterOnce     sync.Once
+	watchingRoster int32
+
+	eventBufferSize int
+	logger          Logger
+	reconnectPolicy ReconnectPolicy
+
+	deadLetterHandler DeadLetterHandler
+	deadLetterDir     string
+	// eventsDefinition is used to warn about listeners for undefined events if set.
+	eventsDefinition *EventsDefinition
+
+	// nextCallbackID is accessed atomically. See newCallbackID.
+	nextCallbackID CallbackID
+}
 
-	running   bool
-	eventChan chan Event
-	err       error
+// Connect connects to the game as the player.
+// Use Dial for further configuration.
+func Connect(gameURL, gameID, playerID, playerSecret string, opts ...DialOption) (*Socket, error) {
+	return Dial(gameURL, WithGame(gameID), WithPlayer(playerID, playerSecret), WithDialOptions(opts...))
+}
 
-	nextCallbackID CallbackID
+// Spectate connects to the game as a spectator.
+// Use Dial for further configuration and to receive the events of the game.
+func Spectate(gameURL, gameID string, opts ...DialOption) error {
+	_, err := Dial(gameURL, WithGame(gameID), WithDialOptions(opts...))
+	return err
 }
 
-func Connect(gameURL, gameID, playerID, playerSecret string) (*Socket, error) {
-	gameURL = trimURL(gameURL)
-	socket := &Socket{
-		gameURL:        gameURL,
-		tls:            isTLS(gameURL),
-		eventListeners: make(map[EventName]map[CallbackID]EventCallback),
-		usernameCache:  make(map[string]string),
-		eventChan:      make(chan Event, 10),
-		gameID:         gameID,
-		playerID:       playerID,
+// ConnectAdditionalClient connects a new socket to the same player as s.
+// The new socket has the same configuration as s and shares the username cache with it,
+// but it has its own event listeners, statistics and no journal.
+// ConnectAdditionalClient panics if s is not connected to a player.
+func (s *Socket) ConnectAdditionalClient() (*Socket, error) {
+	if s.playerID == "" {
+		panic("cannot connect an additional client as a spectator")
 	}
-	err := socket.connect(gameID, playerID, playerSecret)
+
+	socket := newSocket(s.gameURL, s.tls, s.gameID, s.playerID)
+	s.copySettings(socket)
+	err := socket.connect(s.gameID, s.playerID, s.playerSecret)
 	if err != nil {
 		return nil, err
 	}
 
 	socket.startListenLoop()
-
-	socket.usernameCache, err = socket.fetchPlayers(gameID)
-	if err != nil {
-		return nil, err
-	}
+	socket.restartPinging()
 
 	return socket, nil
 }
 
-func Spectate(gameURL, gameID string) error {
-	gameURL = trimURL(gameURL)
-	socket := &Socket{
-		gameURL:        gameURL,
-		tls:            isTLS(gameURL),
-		eventListeners: make(map[EventName]map[CallbackID]EventCallback),
-		usernameCache:  make(map[string]string),
-		eventChan:      make(chan Event, 10),
-		gameID:         gameID,
+// copySettings configures socket like s. Listeners, statistics, the journal and the state of the connection are not copied.
+func (s *Socket) copySettings(socket *Socket) {
+	socket.dialConfig = s.dialConfig
+	socket.protocol = s.protocol
+	socket.logger = s.logger
+	socket.usernames = s.usernames
+	atomic.StoreInt32(&socket.prefetchUsernames, atomic.LoadInt32(&s.prefetchUsernames))
+	socket.eventsDefinition = s.eventsDefinition
+
+	socket.eventBufferSize = s.eventBufferSize
+	socket.eventChan = make(chan Event, s.eventBufferSize)
+	socket.strictDecoding = s.strictDecoding
+	socket.suppressEchoes = s.suppressEchoes
+	socket.dropRawData = s.dropRawData
+	socket.eventTTL = s.eventTTL
+	socket.SetBackpressure(int(atomic.LoadInt32(&s.highWatermark)), int(atomic.LoadInt32(&s.lowWatermark)))
+	sampling, aggregation := s.shaper.settings()
+	for name, n := range sampling {
+		socket.SetSampling(name, n)
 	}
-	err := socket.spectate(gameID)
-	if err != nil {
-		return err
+	for name, window := range aggregation {
+		socket.SetAggregation(name, window)
 	}
 
-	socket.startListenLoop()
+	socket.readTimeout = s.readTimeout
+	socket.writeTimeout = s.writeTimeout
+	socket.heartbeatTimeout = atomic.LoadInt64(&s.heartbeatTimeout)
+	socket.pingInterval = atomic.LoadInt64(&s.pingInterval)
+	socket.reconnectPolicy = s.reconnectPolicy
+	socket.deadLetterHandler = s.deadLetterHandler
+	socket.deadLetterDir = s.deadLetterDir
+}
 
-	socket.usernameCache, err = socket.fetchPlayers(gameID)
-	if err != nil {
-		return err
+func newSocket(gameURL string, tls bool, gameID, playerID string) *Socket {
+	return &Socket{
+		gameURL:         gameURL,
+		tls:             tls,
+		eventListeners:  make(map[EventName]map[CallbackID]EventCallback),
+		dispatchCache:   make(map[EventName][]listener),
+		disconnectCbs:   make(map[CallbackID]func(err error)),
+		closeCbs:        make(map[CallbackID]func()),
+		overflowCbs:     make(map[CallbackID]func(queueLen, capacity int)),
+		eventChan:       make(chan Event, 10),
+		done:            make(chan struct{}),
+		eventBufferSize: 10,
+		stats:           newStatsCollector(),
+		protocol:        flatProtocol{},
+		gameID:          gameID,
+		playerID:        playerID,
 	}
-
-	return nil
 }
 
 // RunEventLoop starts listening for events and triggers registered event listeners.
 // Returns on close or error.
 func (s *Socket) RunEventLoop() error {
-	for s.running {
-		event, ok := <-s.eventChan
-		if !ok {
-			break
-		}
+	eventChan, _ := s.channels()
+	for event := range eventChan {
 		s.triggerEventListeners(event)
 	}
-	if s.err == ErrClosed {
+	err := s.loadErr()
+	if err == ErrClosed {
 		return nil
 	}
-	return s.err
+	return err
 }
 
 // NextEvent returns the next event in the queue or ok = false if there is none.
 // Registered event listeners will be triggered.
 func (s *Socket) NextEvent() (Event, bool, error) {
+	eventChan, _ := s.channels()
 	select {
-	case event, ok := <-s.eventChan:
+	case event, ok := <-eventChan:
 		if ok {
 			s.triggerEventListeners(event)
 			return event, true, nil
 		} else {
-			return Event{}, false, s.err
+			return Event{}, false, s.loadErr()
 		}
 	default:
 		return Event{}, false, nil
 	}
 }
 
+// EventChan returns the channel of received events for use in select statements.
+// Registered event listeners are not triggered for events read from the channel; pass them to Dispatch to do so.
+// The channel is closed when the connection ends. NextEvent then returns the reason.
+// The returned channel is replaced when the socket is reconnected after it has been closed.
+func (s *Socket) EventChan() <-chan Event {
+	eventChan, _ := s.channels()
+	return eventChan
+}
+
+// Dispatch triggers the registered event listeners for event.
+func (s *Socket) Dispatch(event Event) {
+	s.triggerEventListeners(event)
+}
+
+// Done returns a channel that is closed when the listen loop stops because the connection was closed or lost.
+// The returned channel is replaced when the socket is reconnected after it has been closed.
+func (s *Socket) Done() <-chan struct{} {
+	_, done := s.channels()
+	return done
+}
+
+// Err returns nil while the socket is connected, ErrClosed after the connection was closed normally
+// and the error that ended the connection otherwise.
+func (s *Socket) Err() error {
+	_, done := s.channels()
+	select {
+	case <-done:
+		return s.loadErr()
+	default:
+		return nil
+	}
+}
+
+// channels returns the current event channel and the channel closed with it.
+func (s *Socket) channels() (chan Event, chan struct{}) {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.eventChan, s.done
+}
+
+func (s *Socket) loadErr() error {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.err
+}
+
+// connection returns the current websocket connection.
+func (s *Socket) connection() wsConnection {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.wsConn
+}
+
+func (s *Socket) isRunning() bool {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.running
+}
+
+// isCurrent reports whether the listen loop of generation has not been replaced.
+func (s *Socket) isCurrent(generation int) bool {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return generation == s.generation
+}
+
+// isActive reports whether the socket is running and the listen loop of generation has not been replaced.
+func (s *Socket) isActive(generation int) bool {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	return s.running && generation == s.generation
+}
+
 // On registers a callback that is triggered when the event is received.
+// On, Once and RemoveCallback may safely be called from inside callbacks.
 func (s *Socket) On(event EventName, callback EventCallback) CallbackID {
+	s.checkEventName(event)
 	if s.eventListeners[event] == nil {
 		s.eventListeners[event] = make(map[CallbackID]EventCallback)
 	}
 
-	id := s.nextCallbackID
-	s.nextCallbackID++
+	id := s.newCallbackID()
 
 	s.eventListeners[event][id] = callback
+	delete(s.dispatchCache, event)
 
 	return id
 }
 
+// OnAny registers a callback that is triggered for every received event after the listeners of the specific event.
+func (s *Socket) OnAny(callback EventCallback) CallbackID {
+	return s.On(anyEvent, callback)
+}
+
+// OnFrom registers a callback that is triggered when the event is received and was caused by playerID.
+// Events without origin metadata never match. See Event.Origin.
+func (s *Socket) OnFrom(playerID string, event EventName, callback EventCallback) CallbackID {
+	return s.On(event, func(e Event) {
+		if e.Origin == playerID {
+			callback(e)
+		}
+	})
+}
+
+// OnFromServer registers a callback that is triggered when the event is received and was emitted by the server on its own.
+func (s *Socket) OnFromServer(event EventName, callback EventCallback) CallbackID {
+	return s.OnFrom(OriginServer, event, callback)
+}
+
 // Once registers a callback that is triggered only the first time the event is received.
 func (s *Socket) Once(event EventName, callback EventCallback) CallbackID {
+	s.checkEventName(event)
 	if s.eventListeners[event] == nil {
 		s.eventListeners[event] = make(map[CallbackID]EventCallback)
 	}
 
-	id := s.nextCallbackID
-	s.nextCallbackID++
+	id := s.newCallbackID()
 
 	s.eventListeners[event][id] = func(event Event) {
-		callback(event)
+		// Remove the callback first so that it cannot run again if it dispatches events itself.
 		s.RemoveCallback(id)
+		callback(event)
 	}
+	delete(s.dispatchCache, event)
+
+	return id
+}
 
+// OnDisconnect registers a callback that is triggered when the connection is lost because of an error.
+// The callback is invoked from the listen goroutine.
+func (s *Socket) OnDisconnect(callback func(err error)) CallbackID {
+	id := s.newCallbackID()
+	s.lifecycleLock.Lock()
+	s.disconnectCbs[id] = callback
+	s.lifecycleLock.Unlock()
 	return id
 }
 
+// OnClose registers a callback that is triggered when the connection is closed normally by either side.
+// The callback is invoked from the listen goroutine.
+func (s *Socket) OnClose(callback func()) CallbackID {
+	id := s.newCallbackID()
+	s.lifecycleLock.Lock()
+	s.closeCbs[id] = callback
+	s.lifecycleLock.Unlock()
+	return id
+}
+
+// newCallbackID returns a new unique CallbackID. It is safe for concurrent use
+// because some callbacks are registered with their own locks from any goroutine.
+func (s *Socket) newCallbackID() CallbackID {
+	return CallbackID(atomic.AddInt64((*int64)(&s.nextCallbackID), 1) - 1)
+}
+
 // RemoveCallback deletes the callback with the specified id.
+// A callback removed while an event is being dispatched is not called for that event anymore.
 func (s *Socket) RemoveCallback(id CallbackID) {
-	for _, callbacks := range s.eventListeners {
-		delete(callbacks, id)
+	for event, callbacks := range s.eventListeners {
+		if _, ok := callbacks[id]; ok {
+			delete(callbacks, id)
+			delete(s.dispatchCache, event)
+		}
 	}
+	s.lifecycleLock.Lock()
+	delete(s.disconnectCbs, id)
+	delete(s.closeCbs, id)
+	s.lifecycleLock.Unlock()
+	s.removeSendHook(id)
+	s.overflowLock.Lock()
+	delete(s.overflowCbs, id)
+	s.overflowLock.Unlock()
 }
 
 // Send sends a new command to the server.
@@ -174,35 +436,120 @@ func (s *Socket) Send(name CommandName, data any) error {
 		return err
 	}
 
-	jsonData, err := json.Marshal(cmd)
+	hooks := s.currentSendHooks()
+	err = runBeforeSend(hooks, &cmd)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := codec.Marshal(cmd)
 	if err != nil {
 		return err
 	}
 
-	s.wsConn.WriteMessage(websocket.TextMessage, jsonData)
+	s.writeLock.Lock()
+	wsConn := s.connection()
+	if s.writeTimeout > 0 {
+		wsConn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	}
+	err = wsConn.WriteMessage(websocket.TextMessage, jsonData)
+	s.writeLock.Unlock()
+	runAfterSend(hooks, cmd, jsonData, err)
+	if err != nil {
+		return err
+	}
+	s.stats.commandSent()
+	s.writeJournal(clock.Now(), JournalCommand, string(cmd.Name), cmd.Data)
 	return nil
 }
 
+// SendCommand sends a new command with typed data to the server.
+// SendCommand panics if the socket is not connected to a player.
+func SendCommand[T any](s *Socket, name CommandName, data T) error {
+	return s.Send(name, data)
+}
+
+// TypedCommand binds a command name to the type of its data.
+// Generated wrappers can declare their commands as TypedCommand values to get compile-time checking of command payloads.
+type TypedCommand[T any] CommandName
+
+// Send sends the command with data to the server.
+// Send panics if the socket is not connected to a player.
+func (c TypedCommand[T]) Send(s *Socket, data T) error {
+	return SendCommand(s, CommandName(c), data)
+}
+
 // Close closes the underlying websocket connection.
 func (s *Socket) Close() error {
+	s.stateLock.Lock()
 	s.running = false
-	s.wsConn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(5*time.Second))
-	return s.wsConn.Close()
+	wsConn := s.wsConn
+	s.stateLock.Unlock()
+	wsConn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(5*time.Second))
+	return wsConn.Close()
 }
 
-// Username returns the username associated with playerId.
-func (s *Socket) Username(playerID string) string {
-	if username, ok := s.usernameCache[playerID]; ok {
-		return username
+// Leave removes the player from the game, closes the connection and deletes the stored session of the player.
+// Unlike Close, the player cannot be resumed afterwards.
+// Spectators are only disconnected.
+func (s *Socket) Leave() error {
+	if s.playerID == "" {
+		return s.Close()
 	}
 
-	username, err := s.fetchUsername(s.gameID, playerID)
-	if err == nil {
-		s.usernameCache[playerID] = username
+	err := s.deletePlayer()
+	if err != nil {
+		return err
 	}
+
+	s.Close()
+	return DeleteSession(s.gameURL, s.playerID)
+}
+
+// Username returns the username associated with playerId.
+// On a cache miss the whole player list is fetched once and concurrent lookups are deduplicated.
+func (s *Socket) Username(playerID string) string {
+	username, _ := s.usernames.get(playerID)
 	return username
 }
 
+// RefreshUsernames fetches the usernames of all players in the game with a single request.
+func (s *Socket) RefreshUsernames() error {
+	return s.usernames.refresh()
+}
+
+// SetPrefetchUsernames enables/disables refreshing the username cache in the background
+// whenever a NewPlayerEvent is received.
+func (s *Socket) SetPrefetchUsernames(enable bool) {
+	var value int32
+	if enable {
+		value = 1
+	}
+	atomic.StoreInt32(&s.prefetchUsernames, value)
+}
+
+func (s *Socket) newUsernameCache(capacity int) *usernameCache {
+	return newUsernameCache(capacity, func() (map[string]string, error) {
+		return s.fetchPlayers(s.gameID)
+	}, func(playerID string) (string, error) {
+		return s.fetchUsername(s.gameID, playerID)
+	})
+}
+
+// SetStrictDecoding enables/disables strict decoding of event data.
+// In strict mode Event.UnmarshalData fails on unknown fields and on missing fields tagged with `cg:"required"`.
+// This is useful during development to catch schema mismatches between client and server early.
+func (s *Socket) SetStrictDecoding(enable bool) {
+	s.strictDecoding = enable
+}
+
+// Stats returns a snapshot of the traffic handled by the socket.
+func (s *Socket) Stats() Stats {
+	stats := s.stats.snapshot()
+	stats.UsernameCacheHits, stats.UsernameCacheMisses, stats.UsernameCacheSize = s.usernames.stats()
+	return stats
+}
+
 func (s *Socket) GameURL() string {
 	return s.gameURL
 }
@@ -220,49 +567,253 @@ func (s *Socket) IsSpectating() bool {
 }
 
 func (s *Socket) startListenLoop() {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	s.startListenLoopLocked()
+}
+
+// startListenLoopLocked starts a new listen loop, which replaces the current one. s.stateLock must be held.
+// Only the listen loop that ends the socket closes eventChan and done.
+func (s *Socket) startListenLoopLocked() {
 	s.running = true
+	s.err = nil
+	s.generation++
+	generation := s.generation
+	epoch := int(atomic.AddInt32(&s.epoch, 1))
+	wsConn := s.wsConn
+	eventChan := s.eventChan
+	done := s.done
+	s.handlePongs(wsConn)
+	s.extendReadDeadline(wsConn)
 	go func() {
-		for s.running {
-			event, err := s.receiveEvent()
+		for {
+			s.applyBackpressure(wsConn, eventChan, generation)
+			event, err := s.receiveEvent(wsConn)
+			if err == errDeadLetter {
+				continue
+			}
+			event.Epoch = epoch
 			if err != nil {
-				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived, websocket.CloseGoingAway) {
-					s.err = ErrClosed
-				} else {
-					s.err = err
+				reason, ok := s.connectionLost(generation, err)
+				if !ok {
+					// The socket has been reconnected and a new listen loop has taken over.
+					return
+				}
+				if reason != ErrClosed && s.autoReconnect(generation, reason) {
+					return
 				}
-				s.running = false
-				close(s.eventChan)
+				if !s.stop(generation) {
+					return
+				}
+				for _, aggregate := range s.shaper.flush() {
+					s.enqueue(eventChan, aggregate)
+				}
+				close(eventChan)
+				close(done)
+				s.triggerLifecycleCallbacks(reason)
+				return
+			}
+			if !s.isCurrent(generation) {
+				// The socket has been reconnected and a new listen loop has taken over.
+				return
+			}
+			if event.Name == NewPlayerEvent || event.Name == PlayerLeftEvent {
+				sharedRESTCache(s.gameURL).invalidate("games/" + s.gameID + "/players")
+			}
+			if (atomic.LoadInt32(&s.prefetchUsernames) == 1 && event.Name == NewPlayerEvent) ||
+				((atomic.LoadInt32(&s.playerWaiters) > 0 || atomic.LoadInt32(&s.watchingRoster) == 1) && (event.Name == NewPlayerEvent || event.Name == PlayerLeftEvent)) {
+				go s.usernames.refresh()
+			}
+			s.clientSequence++
+			event.ClientSequence = s.clientSequence
+			s.writeJournal(event.ReceivedAt, JournalEvent, string(event.Name), event.Data)
+			if gap, ok := s.checkSequence(event); ok {
+				s.enqueue(eventChan, gap)
+			}
+			if s.isSuppressedEcho(event) {
 				continue
 			}
-			s.eventChan <- event
+			aggregates, dispatch := s.shaper.shape(event)
+			for _, aggregate := range aggregates {
+				s.enqueue(eventChan, aggregate)
+			}
+			if dispatch {
+				if s.dropRawData && event.decoded != nil && !IsStandardEvent(event.Name) {
+					event.Data = nil
+				}
+				s.enqueue(eventChan, event)
+			}
 		}
 	}()
 }
 
-func (s *Socket) receiveEvent() (Event, error) {
-	msgType, msg, err := s.wsConn.ReadMessage()
+func (s *Socket) receiveEvent(wsConn wsConnection) (Event, error) {
+	msgType, reader, err := wsConn.NextReader()
 	if err != nil {
 		return Event{}, err
 	}
+
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	defer func() {
+		if reuseBuffer(buffer.Cap()) {
+			bufferPool.Put(buffer)
+		}
+	}()
+	_, err = buffer.ReadFrom(reader)
+	if err != nil {
+		return Event{}, err
+	}
+	msg := buffer.Bytes()
+
+	s.extendReadDeadline(wsConn)
+	s.stats.received(len(msg))
 	if msgType != websocket.TextMessage {
+		if s.captureDeadLetter(msg, ErrInvalidMessageType) {
+			return Event{}, errDeadLetter
+		}
 		return Event{}, ErrInvalidMessageType
 	}
 
 	var event Event
-	err = json.Unmarshal(msg, &event)
-	if err != nil {
+	err = s.protocol.decodeEvent(msg, &event)
+	if err != nil || event.Name == "" {
+		s.stats.decodeError()
+		if err == nil {
+			err = errors.New("missing event name")
+		}
+		if s.captureDeadLetter(msg, fmt.Errorf("%w: %s", ErrDecodeFailed, err)) {
+			return Event{}, errDeadLetter
+		}
 		return Event{}, ErrDecodeFailed
 	}
-	if event.Name == "" {
-		return Event{}, ErrDecodeFailed
+	if _, ok := codec.(stdCodec); !ok {
+		// encoding/json copies Event.Data, but other codecs may return a slice of the pooled buffer.
+		event.Data = append(json.RawMessage(nil), event.Data...)
 	}
+	s.stats.event(event.Name)
+	event.ReceivedAt = clock.Now()
+	event.strict = s.strictDecoding
+	event.Echo = s.playerID != "" && event.Origin == s.playerID
+	event.decode()
 
 	return event, nil
 }
 
+// connectionLost records why the listen loop of generation stopped reading and returns the reason.
+// It returns false if the listen loop has been replaced in the meantime.
+func (s *Socket) connectionLost(generation int, err error) (error, bool) {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	if generation != s.generation {
+		return nil, false
+	}
+	if s.err != nil {
+		// Reconnect has failed and set the reason.
+		return s.err, true
+	}
+	if !s.running || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived, websocket.CloseGoingAway) {
+		s.err = ErrClosed
+	} else if isTimeout(err) && s.currentHeartbeatTimeout() > 0 {
+		s.err = ErrHeartbeatTimeout
+	} else if isTimeout(err) {
+		s.err = ErrReadTimeout
+	} else {
+		s.err = err
+	}
+	return s.err, true
+}
+
+// stop marks the socket as stopped if the listen loop of generation is still the current one.
+// The caller then owns eventChan and done and has to close them.
+func (s *Socket) stop(generation int) bool {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	if generation != s.generation {
+		return false
+	}
+	s.running = false
+	return true
+}
+
+func (s *Socket) triggerLifecycleCallbacks(reason error) {
+	if reason == ErrClosed {
+		s.lifecycleLock.Lock()
+		callbacks := make([]func(), 0, len(s.closeCbs))
+		for _, cb := range s.closeCbs {
+			callbacks = append(callbacks, cb)
+		}
+		s.lifecycleLock.Unlock()
+		for _, cb := range callbacks {
+			cb()
+		}
+		return
+	}
+	s.lifecycleLock.Lock()
+	callbacks := make([]func(err error), 0, len(s.disconnectCbs))
+	for _, cb := range s.disconnectCbs {
+		callbacks = append(callbacks, cb)
+	}
+	s.lifecycleLock.Unlock()
+	for _, cb := range callbacks {
+		cb(reason)
+	}
+}
+
+// triggerEventListeners calls the listeners of event that were registered when dispatching started.
+// Listeners added during dispatch are first called for the next event and
+// listeners removed during dispatch are not called anymore.
 func (s *Socket) triggerEventListeners(event Event) {
-	listeners := s.eventListeners[event.Name]
-	for _, cb := range listeners {
-		cb(event)
+	if s.expired(event) {
+		s.stats.expiredEvent()
+		return
+	}
+	s.dispatch(event.Name, event)
+	s.dispatch(anyEvent, event)
+}
+
+func (s *Socket) dispatch(name EventName, event Event) {
+	for _, l := range s.listenersOf(name) {
+		if _, ok := s.eventListeners[name][l.id]; !ok {
+			continue
+		}
+		if timer := s.timer; timer != nil {
+			s.callTimed(timer, name, l, event)
+		} else {
+			l.callback(event)
+		}
+	}
+}
+
+type listener struct {
+	id       CallbackID
+	callback EventCallback
+}
+
+// listenersOf returns the listeners of event sorted by CallbackID.
+// The result is cached until the listeners of event change. Cached slices are never modified,
+// which makes them safe to iterate while callbacks change the registered listeners.
+func (s *Socket) listenersOf(event EventName) []listener {
+	if listeners, ok := s.dispatchCache[event]; ok {
+		return listeners
+	}
+
+	callbacks := s.eventListeners[event]
+	if len(callbacks) == 0 {
+		return nil
+	}
+
+	ids := make([]CallbackID, 0, len(callbacks))
+	for id := range callbacks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	listeners := make([]listener, len(ids))
+	for i, id := range ids {
+		listeners[i] = listener{id: id, callback: callbacks[id]}
 	}
+	s.dispatchCache[event] = listeners
+	return listeners
 }
@@ -0,0 +1,110 @@
+package cg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sseKeepAliveInterval = 15 * time.Second
+
+// SSEHandler is an http.Handler that streams the events of a socket as Server-Sent Events.
+// Clients can restrict the stream to specific events with one or more `event` query parameters,
+// e.g. `/events?event=move&event=game_over`.
+// Events are dropped for clients that cannot keep up.
+type SSEHandler struct {
+	lock        sync.Mutex
+	subscribers map[chan Event]map[EventName]struct{}
+}
+
+// NewSSEHandler creates a handler that forwards all events received by socket.
+// Events are only forwarded while the event loop of socket is running.
+func NewSSEHandler(socket *Socket) *SSEHandler {
+	h := &SSEHandler{
+		subscribers: make(map[chan Event]map[EventName]struct{}),
+	}
+	socket.OnAny(h.publish)
+	return h
+}
+
+func (h *SSEHandler) publish(event Event) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for ch, filter := range h.subscribers {
+		if len(filter) > 0 {
+			if _, ok := filter[event.Name]; !ok {
+				continue
+			}
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	filter := make(map[EventName]struct{})
+	for _, name := range r.URL.Query()["event"] {
+		filter[EventName(name)] = struct{}{}
+	}
+
+	ch := make(chan Event, 64)
+	h.lock.Lock()
+	h.subscribers[ch] = filter
+	h.lock.Unlock()
+	defer func() {
+		h.lock.Lock()
+		delete(h.subscribers, ch)
+		h.lock.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event := <-ch:
+			var data bytes.Buffer
+			raw, _ := event.rawData()
+			if json.Compact(&data, raw) != nil || data.Len() == 0 {
+				data.Reset()
+				data.WriteString("null")
+			}
+			writeSSEEvent(w, event.Name, data.Bytes())
+		}
+		flusher.Flush()
+	}
+}
+
+var sseLineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// writeSSEEvent writes an event of the stream. Line breaks would start new fields,
+// so they are removed from name and every line of data is sent in a separate data field.
+func writeSSEEvent(w io.Writer, name EventName, data []byte) {
+	fmt.Fprintf(w, "event: %s\n", strings.NewReplacer("\r", "", "\n", "").Replace(string(name)))
+	for _, line := range strings.Split(sseLineBreaks.Replace(string(data)), "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
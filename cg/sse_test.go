@@ -0,0 +1,28 @@
+package cg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteSSEEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    EventName
+		data     string
+		expected string
+	}{
+		{name: "single line", event: "move", data: `{"x":1}`, expected: "event: move\ndata: {\"x\":1}\n\n"},
+		{name: "line breaks in name", event: "move\r\ndata: injected\nid: 1", data: "null", expected: "event: movedata: injectedid: 1\ndata: null\n\n"},
+		{name: "multi-line data", event: "move", data: "{\n\"x\": 1\r\n}\rend", expected: "event: move\ndata: {\ndata: \"x\": 1\ndata: }\ndata: end\n\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			writeSSEEvent(&b, test.event, []byte(test.data))
+			if b.String() != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, b.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package cg
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Epoch returns the number of the current connection of the socket.
+// It starts at 1 and is incremented on every reconnect. See Event.Epoch.
+func (s *Socket) Epoch() int {
+	return int(atomic.LoadInt32(&s.epoch))
+}
+
+// SetEventTTL makes the socket drop received events instead of triggering listeners
+// if more than ttl has passed since they were received, e.g. because they were buffered during a reconnect.
+// Events returned by NextEvent or read from EventChan are not filtered. Passing 0 disables the check.
+func (s *Socket) SetEventTTL(ttl time.Duration) {
+	atomic.StoreInt64((*int64)(&s.eventTTL), int64(ttl))
+}
+
+// WithEventTTL is equivalent to calling SetEventTTL after connecting.
+func WithEventTTL(ttl time.Duration) Option {
+	return func(config *socketConfig) {
+		config.eventTTL = ttl
+	}
+}
+
+// IsStale reports whether event was received on a previous connection or is older than the TTL set with SetEventTTL.
+// Events that were generated locally are never stale.
+func (s *Socket) IsStale(event Event) bool {
+	if event.ReceivedAt.IsZero() {
+		return false
+	}
+	return event.Epoch != s.Epoch() || s.expired(event)
+}
+
+func (s *Socket) expired(event Event) bool {
+	ttl := time.Duration(atomic.LoadInt64((*int64)(&s.eventTTL)))
+	return ttl > 0 && !event.ReceivedAt.IsZero() && clock.Now().Sub(event.ReceivedAt) > ttl
+}
@@ -0,0 +1,34 @@
+package cg
+
+import "strings"
+
+// Standard events sent by servers implementing CodeGame v0.8 (see CGVersion).
+// Games, players and secrets are managed with the REST API, so these are the only standard events sent over the socket.
+const (
+	// NewPlayerEvent is sent by the server when a new player joins the game. Its data is NewPlayerEventData.
+	NewPlayerEvent EventName = "cg_new_player"
+	// PlayerLeftEvent is sent by the server when a player leaves the game. Its data is PlayerLeftEventData.
+	PlayerLeftEvent EventName = "cg_player_left"
+)
+
+// NewPlayerEventData is the data of NewPlayerEvent. The ID of the new player is the origin of the event.
+type NewPlayerEventData struct {
+	Username string `json:"username"`
+}
+
+// PlayerLeftEventData is the data of PlayerLeftEvent. The ID of the player is the origin of the event.
+type PlayerLeftEventData struct{}
+
+// standardEventPrefix is reserved for standard events and events generated by this package.
+const standardEventPrefix = "cg_"
+
+// IsStandardEvent reports whether name is reserved for standard events or events generated by this package,
+// e.g. SequenceGapEvent.
+func IsStandardEvent(name EventName) bool {
+	return strings.HasPrefix(string(name), standardEventPrefix)
+}
+
+func init() {
+	RegisterEventType[NewPlayerEventData](NewPlayerEvent)
+	RegisterEventType[PlayerLeftEventData](PlayerLeftEvent)
+}
@@ -0,0 +1,77 @@
+package cg
+
+import "sync"
+
+// Reducer applies an event to the state.
+type Reducer[T any] func(state *T, event Event) error
+
+// State maintains a value of type T that is updated by reducers whenever their event is received.
+// All access to the value is synchronized so that it can be read from any goroutine.
+type State[T any] struct {
+	lock    sync.RWMutex
+	value   T
+	socket  *Socket
+	onError func(event Event, err error)
+}
+
+// NewState creates a new state with an initial value that is updated by events received by socket.
+func NewState[T any](socket *Socket, initial T) *State[T] {
+	return &State[T]{
+		value:  initial,
+		socket: socket,
+	}
+}
+
+// Reduce registers a reducer for event.
+// Reducers run before all event listeners registered after the call to Reduce.
+func (s *State[T]) Reduce(event EventName, reducer Reducer[T]) CallbackID {
+	return s.socket.On(event, func(e Event) {
+		s.lock.Lock()
+		err := reducer(&s.value, e)
+		s.lock.Unlock()
+		if err != nil && s.onError != nil {
+			s.onError(e, err)
+		}
+	})
+}
+
+// ReduceData registers a reducer for event that receives the decoded event data.
+func ReduceData[T, D any](s *State[T], event EventName, reducer func(state *T, data D)) CallbackID {
+	return s.Reduce(event, func(state *T, e Event) error {
+		var data D
+		err := e.UnmarshalData(&data)
+		if err != nil {
+			return err
+		}
+		reducer(state, data)
+		return nil
+	})
+}
+
+// OnError sets a callback that is triggered when a reducer returns an error.
+func (s *State[T]) OnError(callback func(event Event, err error)) {
+	s.onError = callback
+}
+
+// Snapshot returns a copy of the current value.
+// Maps, slices and pointers inside the value are not copied. Use Read to access them safely.
+func (s *State[T]) Snapshot() T {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.value
+}
+
+// Read calls fn with the current value while holding a read lock.
+// fn must not retain references to the value after it returns.
+func (s *State[T]) Read(fn func(state *T)) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	fn(&s.value)
+}
+
+// Update calls fn with the current value while holding a write lock.
+func (s *State[T]) Update(fn func(state *T)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	fn(&s.value)
+}
@@ -0,0 +1,129 @@
+package cg
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stats is a snapshot of the traffic handled by a socket.
+type Stats struct {
+	// EventCounts contains the number of received events per event name.
+	EventCounts   map[EventName]int
+	BytesReceived int64
+	CommandsSent  int
+	DecodeErrors  int
+	// ExpiredEvents is the number of events that were dropped because they exceeded the TTL set with SetEventTTL.
+	ExpiredEvents int
+	// DroppedRosterChanges is the number of changes that were dropped because the channel returned by RosterChanges was full.
+	DroppedRosterChanges int
+	Uptime               time.Duration
+
+	// The username cache is shared with additional clients of the same player.
+	UsernameCacheHits   int
+	UsernameCacheMisses int
+	UsernameCacheSize   int
+}
+
+// EventCount is the number of times an event was received.
+type EventCount struct {
+	Name  EventName
+	Count int
+}
+
+// TopEvents returns the n most frequently received events in descending order.
+// If n <= 0 all events are returned.
+func (s Stats) TopEvents(n int) []EventCount {
+	counts := make([]EventCount, 0, len(s.EventCounts))
+	for name, count := range s.EventCounts {
+		counts = append(counts, EventCount{Name: name, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count == counts[j].Count {
+			return counts[i].Name < counts[j].Name
+		}
+		return counts[i].Count > counts[j].Count
+	})
+	if n > 0 && n < len(counts) {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+type statsCollector struct {
+	lock          sync.Mutex
+	startTime     time.Time
+	eventCounts   map[EventName]int
+	bytesReceived int64
+	commandsSent  int
+	decodeErrors  int
+	expiredEvents int
+	rosterDrops   int
+}
+
+func newStatsCollector() *statsCollector {
+	return &statsCollector{
+		startTime:   clock.Now(),
+		eventCounts: make(map[EventName]int),
+	}
+}
+
+func (c *statsCollector) received(size int) {
+	c.lock.Lock()
+	c.bytesReceived += int64(size)
+	c.lock.Unlock()
+}
+
+func (c *statsCollector) event(name EventName) {
+	c.lock.Lock()
+	c.eventCounts[name]++
+	c.lock.Unlock()
+}
+
+func (c *statsCollector) commandSent() {
+	c.lock.Lock()
+	c.commandsSent++
+	c.lock.Unlock()
+}
+
+func (c *statsCollector) decodeError() {
+	c.lock.Lock()
+	c.decodeErrors++
+	c.lock.Unlock()
+}
+
+func (c *statsCollector) expiredEvent() {
+	c.lock.Lock()
+	c.expiredEvents++
+	c.lock.Unlock()
+}
+
+func (c *statsCollector) rosterDrop() {
+	c.lock.Lock()
+	c.rosterDrops++
+	c.lock.Unlock()
+}
+
+func (c *statsCollector) expired() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.expiredEvents
+}
+
+func (c *statsCollector) snapshot() Stats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	counts := make(map[EventName]int, len(c.eventCounts))
+	for name, count := range c.eventCounts {
+		counts[name] = count
+	}
+	return Stats{
+		EventCounts:          counts,
+		BytesReceived:        c.bytesReceived,
+		CommandsSent:         c.commandsSent,
+		DecodeErrors:         c.decodeErrors,
+		ExpiredEvents:        c.expiredEvents,
+		DroppedRosterChanges: c.rosterDrops,
+		Uptime:               clock.Now().Sub(c.startTime),
+	}
+}
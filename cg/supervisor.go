@@ -0,0 +1,86 @@
+package cg
+
+import (
+	"context"
+	"time"
+)
+
+// SupervisorPolicy controls how Supervise restarts a bot.
+type SupervisorPolicy struct {
+	// MaxRestarts is the maximum number of consecutive restarts. 0 disables restarting.
+	MaxRestarts int
+	// Delay is the time to wait before the first restart. It is doubled after every consecutive restart.
+	Delay time.Duration
+	// MaxDelay caps the delay between restarts. 0 means no limit.
+	MaxDelay time.Duration
+	// ResetAfter resets the restart count and thereby the delay once a socket has stayed connected for this long.
+	// 0 means the count is never reset.
+	ResetAfter time.Duration
+	// Backoff overrides Delay and MaxDelay if set.
+	Backoff BackoffStrategy
+	// OnRestart is called before every restart with the number of the restart and the error that caused it. Optional.
+	OnRestart func(restart int, err error)
+}
+
+// Supervise calls start to create and set up a socket and runs its event loop.
+// When start fails or the connection ends with an error, the socket is recreated by calling start again
+// according to policy. start should register all callbacks of the bot, because they are not carried over.
+// Supervise returns nil when a connection is closed normally, ctx.Err() when ctx is done,
+// in which case the current socket is shut down, and the last error once no restarts are left.
+func Supervise(ctx context.Context, start func() (*Socket, error), policy SupervisorPolicy) error {
+	backoff := policy.Backoff
+	if backoff == nil {
+		backoff = ExponentialBackoff{Initial: policy.Delay, Max: policy.MaxDelay}
+	}
+	restarts := 0
+	for {
+		began := clock.Now()
+		err := superviseOnce(ctx, start)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		if policy.ResetAfter > 0 && clock.Now().Sub(began) >= policy.ResetAfter {
+			restarts = 0
+		}
+		if restarts >= policy.MaxRestarts {
+			return err
+		}
+		restarts++
+		if policy.OnRestart != nil {
+			policy.OnRestart(restarts, err)
+		}
+
+		select {
+		case <-clock.After(backoff.NextDelay(restarts)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// superviseOnce runs a single socket created by start until it ends or ctx is done.
+func superviseOnce(ctx context.Context, start func() (*Socket, error)) error {
+	socket, err := start()
+	if err != nil {
+		return err
+	}
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			socket.Shutdown(shutdownCtx)
+		case <-stopped:
+		}
+	}()
+
+	err = socket.RunEventLoop()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
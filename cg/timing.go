@@ -0,0 +1,81 @@
+package cg
+
+import (
+	"sync"
+	"time"
+)
+
+// CallbackTiming contains the aggregated execution times of an event callback.
+type CallbackTiming struct {
+	// Event is the event the callback is registered for. It is empty for OnAny callbacks.
+	Event EventName
+	Calls int
+	Total time.Duration
+	Max   time.Duration
+}
+
+// Average returns the mean execution time of the callback.
+func (t CallbackTiming) Average() time.Duration {
+	if t.Calls == 0 {
+		return 0
+	}
+	return t.Total / time.Duration(t.Calls)
+}
+
+type callbackTimer struct {
+	lock          sync.Mutex
+	slowThreshold time.Duration
+	timings       map[CallbackID]CallbackTiming
+}
+
+// SetCallbackTiming enables/disables measuring the execution time of every event callback.
+// If slowThreshold > 0, a warning is logged through the logger of the socket whenever a callback takes longer.
+// Disabling timing discards all collected timings.
+func (s *Socket) SetCallbackTiming(enable bool, slowThreshold time.Duration) {
+	if !enable {
+		s.timer = nil
+		return
+	}
+	s.timer = &callbackTimer{
+		slowThreshold: slowThreshold,
+		timings:       make(map[CallbackID]CallbackTiming),
+	}
+}
+
+// CallbackTimings returns the execution times of all callbacks that have been called since timing was enabled.
+// It returns nil if timing is disabled.
+func (s *Socket) CallbackTimings() map[CallbackID]CallbackTiming {
+	timer := s.timer
+	if timer == nil {
+		return nil
+	}
+	timer.lock.Lock()
+	defer timer.lock.Unlock()
+	timings := make(map[CallbackID]CallbackTiming, len(timer.timings))
+	for id, timing := range timer.timings {
+		timings[id] = timing
+	}
+	return timings
+}
+
+// callTimed calls l with event and records its execution time.
+func (s *Socket) callTimed(timer *callbackTimer, name EventName, l listener, event Event) {
+	start := clock.Now()
+	l.callback(event)
+	duration := clock.Now().Sub(start)
+
+	timer.lock.Lock()
+	timing := timer.timings[l.id]
+	timing.Event = name
+	timing.Calls++
+	timing.Total += duration
+	if duration > timing.Max {
+		timing.Max = duration
+	}
+	timer.timings[l.id] = timing
+	timer.lock.Unlock()
+
+	if timer.slowThreshold > 0 && duration > timer.slowThreshold {
+		s.logf("cg: callback %d for event '%s' took %s", l.id, event.Name, duration)
+	}
+}
@@ -0,0 +1,52 @@
+//go:build !js
+
+package cg
+
+import (
+	"crypto/tls"
+	"net"
+	neturl "net/url"
+	"time"
+)
+
+// isTLS verifies the TLS certificate of a trimmed URL.
+func isTLS(trimmedURL string) bool {
+	return probeTLS(trimmedURL, newDialConfig(trimmedURL, nil))
+}
+
+// probeTLS verifies the TLS certificate of a trimmed URL using the timeouts of config.
+func probeTLS(trimmedURL string, config dialConfig) (isTLS bool) {
+	if isUnixURL(trimmedURL) {
+		return false
+	}
+	if len(config.pinnedKeys) > 0 {
+		// Never fall back to plain text when keys are pinned, so that a failing probe cannot be used for a downgrade.
+		return true
+	}
+	url, err := neturl.Parse("https://" + trimmedURL)
+	if err != nil {
+		return false
+	}
+	host := url.Host
+	if url.Port() == "" {
+		host = host + ":443"
+	}
+
+	timeout := config.dialTimeout + config.tlsHandshakeTimeout
+	if timeout <= 0 || timeout > 5*time.Second {
+		timeout = 5 * time.Second
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", host, config.tlsConfig(""))
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	err = conn.VerifyHostname(url.Hostname())
+	if err != nil {
+		return false
+	}
+
+	expiry := conn.ConnectionState().PeerCertificates[0].NotAfter
+	return !time.Now().After(expiry)
+}
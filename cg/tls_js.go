@@ -0,0 +1,19 @@
+//go:build js && wasm
+
+package cg
+
+import "syscall/js"
+
+func probeTLS(trimmedURL string, config dialConfig) bool {
+	return isTLS(trimmedURL)
+}
+
+// isTLS reports whether the page was loaded over HTTPS.
+// Browsers do not allow probing certificates and block insecure requests from secure pages anyway.
+func isTLS(trimmedURL string) bool {
+	location := js.Global().Get("location")
+	if location.IsUndefined() {
+		return false
+	}
+	return location.Get("protocol").String() == "https:"
+}
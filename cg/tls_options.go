@@ -0,0 +1,109 @@
+package cg
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var ErrCertificatePinMismatch = errors.New("no certificate of the server matches a pinned public key")
+
+// WithPinnedPublicKeys only accepts servers presenting a certificate chain that contains one of the public keys.
+// Each pin is the base64-encoded SHA-256 hash of a DER-encoded SubjectPublicKeyInfo, optionally prefixed with "sha256/",
+// e.g. as printed by `openssl x509 -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64`.
+// Pinning applies to websocket connections and to all REST requests made with the dial options.
+// TLS is always used when keys are pinned.
+// It is not supported in the browser.
+func WithPinnedPublicKeys(pins ...string) DialOption {
+	return func(config *dialConfig) {
+		for _, pin := range pins {
+			config.pinnedKeys = append(config.pinnedKeys, strings.TrimPrefix(pin, "sha256/"))
+		}
+	}
+}
+
+// WithClientCertificates presents certs to servers that require mutual TLS, e.g. for protected debug endpoints.
+// Use tls.LoadX509KeyPair to load a certificate and its key from PEM files.
+// Client certificates are not supported in the browser.
+func WithClientCertificates(certs ...tls.Certificate) DialOption {
+	return func(config *dialConfig) {
+		config.clientCertificates = append(config.clientCertificates, certs...)
+	}
+}
+
+// tlsConfig returns the TLS configuration for connecting to serverName.
+func (c dialConfig) tlsConfig(serverName string) *tls.Config {
+	config := &tls.Config{
+		ServerName:   serverName,
+		Certificates: c.clientCertificates,
+		RootCAs:      c.rootCAs,
+	}
+	if len(c.pinnedKeys) > 0 {
+		pins := c.pinnedKeys
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPinnedKeys(state, pins)
+		}
+	}
+	return config
+}
+
+// tlsDialer returns a function that establishes TLS connections with the configuration of c using netDialer.
+func (c dialConfig) tlsDialer(netDialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := netDialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		tlsConn := tls.Client(conn, c.tlsConfig(host))
+		if c.tlsHandshakeTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.tlsHandshakeTimeout)
+			defer cancel()
+		}
+		err = tlsConn.HandshakeContext(ctx)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// customTLS reports whether tlsConfig differs from the default configuration.
+func (c dialConfig) customTLS() bool {
+	return len(c.pinnedKeys) > 0 || len(c.clientCertificates) > 0
+}
+
+// httpClient returns the client for REST requests that honors the TLS options and the token source of c.
+func (c dialConfig) httpClient() *http.Client {
+	if c.client == nil {
+		return httpClient
+	}
+	return c.client
+}
+
+// verifyPinnedKeys checks the verified chains only. The other certificates sent by the server are not trusted,
+// so a server with a certificate of another CA could otherwise pass by appending a pinned certificate.
+func verifyPinnedKeys(state tls.ConnectionState, pins []string) error {
+	for _, chain := range state.VerifiedChains {
+		for _, cert := range chain {
+			hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			encoded := base64.StdEncoding.EncodeToString(hash[:])
+			for _, pin := range pins {
+				if pin == encoded {
+					return nil
+				}
+			}
+		}
+	}
+	return ErrCertificatePinMismatch
+}
@@ -0,0 +1,179 @@
+package cg
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert creates a certificate for 127.0.0.1 signed by parent or a self-signed CA if parent is nil.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) pin() string {
+	hash := sha256.Sum256(c.cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// newPinTestServer starts a TLS server presenting chain with the key of the first certificate.
+// The returned counter is incremented for every request that reaches the handler.
+func newPinTestServer(t *testing.T, chain ...*testCert) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"player_id":"player","player_secret":"secret"}`))
+	}))
+	certificate := tls.Certificate{PrivateKey: chain[0].key}
+	for _, c := range chain {
+		certificate.Certificate = append(certificate.Certificate, c.cert.Raw)
+	}
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	// Rejected handshakes are expected.
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func withRootCAs(cas ...*testCert) DialOption {
+	return func(config *dialConfig) {
+		config.rootCAs = x509.NewCertPool()
+		for _, ca := range cas {
+			config.rootCAs.AddCert(ca.cert)
+		}
+	}
+}
+
+func TestPinnedKeys(t *testing.T) {
+	realCA := newTestCert(t, "real CA", nil)
+	realLeaf := newTestCert(t, "real", realCA)
+	rogueCA := newTestCert(t, "rogue CA", nil)
+	rogueLeaf := newTestCert(t, "rogue", rogueCA)
+
+	tests := []struct {
+		name   string
+		chain  []*testCert
+		pin    *testCert
+		accept bool
+	}{
+		{name: "pinned leaf", chain: []*testCert{realLeaf}, pin: realLeaf, accept: true},
+		{name: "pinned CA", chain: []*testCert{realLeaf}, pin: realCA, accept: true},
+		{name: "unpinned leaf", chain: []*testCert{rogueLeaf}, pin: realLeaf},
+		{name: "unpinned leaf followed by pinned unchained certificate", chain: []*testCert{rogueLeaf, realLeaf}, pin: realLeaf},
+		{name: "unpinned leaf followed by pinned unchained CA", chain: []*testCert{rogueLeaf, realCA}, pin: realCA},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, requests := newPinTestServer(t, test.chain...)
+			config := newDialConfig(trimURL(server.URL), []DialOption{WithPinnedPublicKeys(test.pin.pin()), withRootCAs(realCA, rogueCA)})
+			resp, err := config.httpClient().Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if test.accept {
+				if err != nil {
+					t.Fatalf("expected the server to be accepted, got %s", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrCertificatePinMismatch) {
+				t.Fatalf("expected %v, got %v", ErrCertificatePinMismatch, err)
+			}
+			if n := atomic.LoadInt32(requests); n != 0 {
+				t.Errorf("expected no request to reach the server, got %d", n)
+			}
+		})
+	}
+}
+
+func TestRESTRequestsUsePinnedKeys(t *testing.T) {
+	ca := newTestCert(t, "CA", nil)
+	pinned := newTestCert(t, "pinned", ca)
+	unpinned := newTestCert(t, "unpinned", ca)
+	pinnedServer, _ := newPinTestServer(t, pinned)
+	unpinnedServer, requests := newPinTestServer(t, unpinned)
+	opts := []DialOption{WithPinnedPublicKeys(pinned.pin()), withRootCAs(ca)}
+
+	_, _, err := JoinGame(unpinnedServer.URL, "game", "alice", "", opts...)
+	if !errors.Is(err, ErrCertificatePinMismatch) {
+		t.Errorf("JoinGame: expected %v, got %v", ErrCertificatePinMismatch, err)
+	}
+	_, _, err = CreateGame(unpinnedServer.URL, false, false, nil, opts...)
+	if !errors.Is(err, ErrCertificatePinMismatch) {
+		t.Errorf("CreateGame: expected %v, got %v", ErrCertificatePinMismatch, err)
+	}
+
+	socket := newSocket(trimURL(unpinnedServer.URL), true, "game", "player")
+	socket.dialConfig = newDialConfig(trimURL(unpinnedServer.URL), opts)
+	_, err = socket.requestPlayers("game")
+	if !errors.Is(err, ErrCertificatePinMismatch) {
+		t.Errorf("requestPlayers: expected %v, got %v", ErrCertificatePinMismatch, err)
+	}
+	_, _, err = socket.MeasureLatency(context.Background())
+	if !errors.Is(err, ErrCertificatePinMismatch) {
+		t.Errorf("MeasureLatency: expected %v, got %v", ErrCertificatePinMismatch, err)
+	}
+	if n := atomic.LoadInt32(requests); n != 0 {
+		t.Errorf("expected no request to reach the unpinned server, got %d", n)
+	}
+
+	_, _, err = CreateGame(pinnedServer.URL, false, false, nil, opts...)
+	if err != nil {
+		t.Errorf("expected the pinned server to be accepted, got %s", err)
+	}
+}
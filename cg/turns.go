@@ -0,0 +1,128 @@
+package cg
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrNotYourTurn = errors.New("it is not your turn")
+
+// TurnManager keeps track of whose turn it is in a turn-based game.
+// Once attached to a socket, Socket.Send returns ErrNotYourTurn for move commands sent outside of the player's turn.
+type TurnManager struct {
+	socket       *Socket
+	moveCommands map[CommandName]struct{}
+
+	lock    sync.Mutex
+	current string
+	// moved is set when a move has been sent successfully in the current turn.
+	moved          bool
+	oneMovePerTurn bool
+	changed        chan struct{}
+}
+
+// NewTurnManager attaches a turn manager to socket.
+// turnEvent is the event announcing the next turn and currentPlayer extracts the ID of the player whose turn it is from it.
+// moveCommands are the commands that may only be sent during the player's turn.
+func NewTurnManager(socket *Socket, turnEvent EventName, currentPlayer func(event Event) (string, error), moveCommands ...CommandName) *TurnManager {
+	t := &TurnManager{
+		socket:       socket,
+		moveCommands: make(map[CommandName]struct{}, len(moveCommands)),
+		changed:      make(chan struct{}),
+	}
+	for _, cmd := range moveCommands {
+		t.moveCommands[cmd] = struct{}{}
+	}
+
+	socket.On(turnEvent, func(event Event) {
+		playerID, err := currentPlayer(event)
+		if err != nil {
+			return
+		}
+		t.lock.Lock()
+		t.current = playerID
+		t.moved = false
+		close(t.changed)
+		t.changed = make(chan struct{})
+		t.lock.Unlock()
+	})
+	socket.BeforeSend(func(cmd *Command) error {
+		return t.checkCommand(cmd.Name)
+	})
+	socket.AfterSend(func(cmd Command, _ []byte, err error) {
+		if err == nil {
+			t.recordMove(cmd.Name)
+		}
+	})
+	return t
+}
+
+// SetOneMovePerTurn makes Socket.Send return ErrNotYourTurn for move commands
+// after a move has already been sent successfully in the current turn. Disabled by default.
+func (t *TurnManager) SetOneMovePerTurn(enable bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.oneMovePerTurn = enable
+}
+
+// CurrentPlayer returns the ID of the player whose turn it is or an empty string if no turn event was received yet.
+func (t *TurnManager) CurrentPlayer() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.current
+}
+
+// IsMyTurn reports whether it is the turn of the player of the socket.
+// With SetOneMovePerTurn it also reports whether no move has been sent yet.
+func (t *TurnManager) IsMyTurn() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.isMyTurn()
+}
+
+func (t *TurnManager) isMyTurn() bool {
+	return t.current != "" && t.current == t.socket.playerID && !(t.oneMovePerTurn && t.moved)
+}
+
+// WaitForMyTurn blocks until it is the turn of the player of the socket or ctx expires.
+// Turn events are only processed while the event loop is running.
+func (t *TurnManager) WaitForMyTurn(ctx context.Context) error {
+	for {
+		t.lock.Lock()
+		myTurn := t.isMyTurn()
+		changed := t.changed
+		t.lock.Unlock()
+		if myTurn {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// checkCommand returns ErrNotYourTurn if name is a move command that must not be sent right now.
+func (t *TurnManager) checkCommand(name CommandName) error {
+	if _, ok := t.moveCommands[name]; !ok {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.isMyTurn() {
+		return ErrNotYourTurn
+	}
+	return nil
+}
+
+// recordMove marks the current turn as used if name is a move command.
+func (t *TurnManager) recordMove(name CommandName) {
+	if _, ok := t.moveCommands[name]; !ok {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.moved = true
+}
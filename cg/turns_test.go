@@ -0,0 +1,84 @@
+package cg_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func newTurnTest(t *testing.T) (*cgtest.Server, *cg.Socket, *cg.TurnManager) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+	turns := cg.NewTurnManager(socket, "turn", func(event cg.Event) (string, error) {
+		var data struct {
+			Player string `json:"player"`
+		}
+		err := event.UnmarshalData(&data)
+		return data.Player, err
+	}, "move")
+	return server, socket, turns
+}
+
+func startTurn(t *testing.T, server *cgtest.Server, socket *cg.Socket, playerID string) {
+	t.Helper()
+	server.Emit("turn", map[string]string{"player": playerID})
+	cgtest.ExpectEvent(t, socket, "turn", time.Second)
+}
+
+func TestTurnManager(t *testing.T) {
+	server, socket, turns := newTurnTest(t)
+
+	if err := socket.Send("move", nil); err != cg.ErrNotYourTurn {
+		t.Fatalf("expected ErrNotYourTurn before the first turn, got %v", err)
+	}
+	startTurn(t, server, socket, "someone_else")
+	if err := socket.Send("move", nil); err != cg.ErrNotYourTurn {
+		t.Fatalf("expected ErrNotYourTurn during another turn, got %v", err)
+	}
+	if err := socket.Send("chat", nil); err != nil {
+		t.Fatalf("expected other commands to be sent, got %v", err)
+	}
+
+	startTurn(t, server, socket, socket.PlayerID())
+	if !turns.IsMyTurn() {
+		t.Fatal("expected IsMyTurn to be true")
+	}
+	for i := 0; i < 2; i++ {
+		if err := socket.Send("move", nil); err != nil {
+			t.Fatalf("expected move %d to be sent, got %v", i+1, err)
+		}
+	}
+}
+
+func TestTurnManagerOneMovePerTurn(t *testing.T) {
+	server, socket, turns := newTurnTest(t)
+	turns.SetOneMovePerTurn(true)
+	errVeto := errors.New("veto")
+	veto := socket.BeforeSend(func(cmd *cg.Command) error {
+		return errVeto
+	})
+
+	startTurn(t, server, socket, socket.PlayerID())
+	if err := socket.Send("move", nil); err != errVeto {
+		t.Fatalf("expected the veto, got %v", err)
+	}
+	socket.RemoveCallback(veto)
+	// The vetoed move does not use up the turn.
+	if err := socket.Send("move", nil); err != nil {
+		t.Fatalf("expected the move to be sent, got %v", err)
+	}
+	if err := socket.Send("move", nil); err != cg.ErrNotYourTurn {
+		t.Fatalf("expected ErrNotYourTurn for a second move, got %v", err)
+	}
+	if turns.IsMyTurn() {
+		t.Error("expected IsMyTurn to be false after the move")
+	}
+
+	startTurn(t, server, socket, socket.PlayerID())
+	if err := socket.Send("move", nil); err != nil {
+		t.Fatalf("expected the move of the next turn to be sent, got %v", err)
+	}
+}
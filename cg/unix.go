@@ -0,0 +1,62 @@
+package cg
+
+import (
+	"context"
+	"encoding/hex"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// Unix domain sockets are addressed with URLs like unix:///path/to/socket.
+// Internally the socket path is hex-encoded into a host name below the reserved .invalid TLD,
+// so that it survives URL formatting and can be recognized by the dialers.
+const unixHostSuffix = ".unix.invalid"
+
+func unixHost(socketPath string) string {
+	return hex.EncodeToString([]byte(socketPath)) + unixHostSuffix
+}
+
+// unixSocketPath returns the socket path encoded in a host or host:port address.
+func unixSocketPath(addr string) (string, bool) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if !strings.HasSuffix(host, unixHostSuffix) {
+		return "", false
+	}
+	path, err := hex.DecodeString(strings.TrimSuffix(host, unixHostSuffix))
+	if err != nil {
+		return "", false
+	}
+	return string(path), true
+}
+
+// isUnixURL reports whether a trimmed URL refers to a unix domain socket.
+func isUnixURL(trimmedURL string) bool {
+	host, _, _ := strings.Cut(trimmedURL, "/")
+	_, ok := unixSocketPath(host)
+	return ok
+}
+
+type dialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// unixAwareDialer wraps dial so that addresses of unix domain sockets are dialed with the unix network.
+func unixAwareDialer(dial dialContextFunc) dialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if path, ok := unixSocketPath(addr); ok {
+			return dial(ctx, "unix", path)
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// proxyFromEnvironment is http.ProxyFromEnvironment except that unix domain sockets are always dialed directly.
+func proxyFromEnvironment(req *http.Request) (*neturl.URL, error) {
+	if _, ok := unixSocketPath(req.URL.Host); ok {
+		return nil, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
@@ -0,0 +1,64 @@
+//go:build !js
+
+package cg
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestUnixSocketBypassesProxy(t *testing.T) {
+	// http.ProxyFromEnvironment reads the environment only once, so the test runs in a new process.
+	if os.Getenv("CG_TEST_UNIX_PROXY") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestUnixSocketBypassesProxy$", "-test.v")
+		cmd.Env = append(os.Environ(),
+			"CG_TEST_UNIX_PROXY=1",
+			"HTTP_PROXY=http://127.0.0.1:1",
+			"HTTPS_PROXY=http://127.0.0.1:1",
+			"NO_PROXY=",
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s\n%s", err, output)
+		}
+		return
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "game.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix domain sockets are not supported: %s", err)
+	}
+	upgrader := websocket.Upgrader{}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/socket" {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err == nil {
+				conn.Close()
+			}
+		}
+	})}
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		server.Close()
+	})
+
+	trimmedURL := trimURL("unix://" + socketPath)
+	resp, err := httpClient.Get("http://" + trimmedURL + "/api/info")
+	if err != nil {
+		t.Fatalf("REST request through the proxy: %s", err)
+	}
+	resp.Body.Close()
+
+	conn, err := dialWebsocket("ws://"+trimmedURL+"/api/socket", newDialConfig(trimmedURL, nil))
+	if err != nil {
+		t.Fatalf("websocket dial through the proxy: %s", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,33 @@
+package cg_test
+
+import (
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestUsernameCacheSize(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice", cg.WithUsernameCacheSize(2))
+	bob, _ := server.AddPlayer("bob")
+	carol, _ := server.AddPlayer("carol")
+
+	for playerID, expected := range map[string]string{bob: "bob", carol: "carol"} {
+		if username := socket.Username(playerID); username != expected {
+			t.Errorf("expected %s, got %q", expected, username)
+		}
+	}
+	// Refreshes put the players into the cache in random order, so carol may have been evicted.
+	// The second lookup hits the cache either way.
+	socket.Username(carol)
+	socket.Username(carol)
+
+	stats := socket.Stats()
+	if stats.UsernameCacheSize != 2 {
+		t.Errorf("expected the cache to be bounded to 2 usernames, got %d", stats.UsernameCacheSize)
+	}
+	if stats.UsernameCacheHits == 0 || stats.UsernameCacheMisses == 0 {
+		t.Errorf("expected hits and misses, got %d hits and %d misses", stats.UsernameCacheHits, stats.UsernameCacheMisses)
+	}
+}
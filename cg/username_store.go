@@ -0,0 +1,67 @@
+package cg
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// usernamesFile stores the last used username of every game server in the data directory.
+const usernamesFile = "usernames.json"
+
+var usernamesLock sync.Mutex
+
+// DefaultUsername returns the username last used to join a game on the server at gameURL
+// and falls back to the preferred username of the config file.
+func DefaultUsername(gameURL string) string {
+	usernamesLock.Lock()
+	usernames, _ := loadUsernames()
+	usernamesLock.Unlock()
+	if username, ok := usernames[trimURL(gameURL)]; ok {
+		return username
+	}
+	return Config().Username
+}
+
+// RememberUsername stores username as the default username for the server at gameURL.
+// It is not called by JoinGame, so applications decide which usernames are remembered, e.g. after a successful join.
+func RememberUsername(gameURL, username string) error {
+	usernamesLock.Lock()
+	defer usernamesLock.Unlock()
+	usernames, err := loadUsernames()
+	if err != nil {
+		return err
+	}
+	usernames[trimURL(gameURL)] = username
+
+	dir, err := dataDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(usernames, "", "  ")
+	if err != nil {
+		return ErrEncodeFailed
+	}
+	return os.WriteFile(filepath.Join(dir, usernamesFile), data, 0o644)
+}
+
+func loadUsernames() (map[string]string, error) {
+	root, err := dataRoot()
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(root, usernamesFile))
+	if os.IsNotExist(err) {
+		return usernames, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &usernames)
+	if err != nil {
+		return nil, ErrDecodeFailed
+	}
+	return usernames, nil
+}
@@ -0,0 +1,31 @@
+package cg_test
+
+import (
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+func TestJoinGameDoesNotRememberUsername(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+	t.Setenv("HOME", dir)
+	server := cgtest.NewServer(t)
+
+	_, _, err := cg.JoinGame(server.URL, server.GameID, "alice", "")
+	if err != nil {
+		t.Fatalf("failed to join: %s", err)
+	}
+	if username := cg.DefaultUsername(server.URL); username == "alice" {
+		t.Error("expected JoinGame not to remember the username")
+	}
+
+	err = cg.RememberUsername(server.URL, "alice")
+	if err != nil {
+		t.Fatalf("failed to remember the username: %s", err)
+	}
+	if username := cg.DefaultUsername(server.URL); username != "alice" {
+		t.Errorf("expected the remembered username alice, got %q", username)
+	}
+}
@@ -0,0 +1,155 @@
+package cg
+
+import "sync"
+
+// defaultUsernameCacheSize is the default maximum number of usernames kept in a usernameCache.
+const defaultUsernameCacheSize = 1024
+
+// usernameCache stores the usernames of the players in a game.
+// The least recently used usernames are evicted when the cache is full.
+// Concurrent lookups of the same player and concurrent refreshes are deduplicated.
+type usernameCache struct {
+	lock      sync.RWMutex
+	usernames *lruCache[string]
+	hits      int
+	misses    int
+	// players contains the IDs of the players currently in the game as of the last refresh.
+	players map[string]struct{}
+	// changed is closed and replaced whenever players changes.
+	changed chan struct{}
+	// roster contains the usernames of all players as of the last refresh while onRoster is set.
+	roster   map[string]string
+	onRoster func(RosterEvent)
+
+	fetchAll func() (map[string]string, error)
+	fetchOne func(playerID string) (string, error)
+
+	refreshes flightGroup[map[string]string]
+	lookups   flightGroup[string]
+}
+
+func newUsernameCache(capacity int, fetchAll func() (map[string]string, error), fetchOne func(playerID string) (string, error)) *usernameCache {
+	return &usernameCache{
+		usernames: newLRUCache[string](capacity),
+		players:   make(map[string]struct{}),
+		changed:   make(chan struct{}),
+		fetchAll:  fetchAll,
+		fetchOne:  fetchOne,
+	}
+}
+
+// get returns the username of playerID.
+// On a cache miss the whole player map is refreshed once before falling back to fetching the single player.
+func (c *usernameCache) get(playerID string) (string, error) {
+	username, ok := c.cached(playerID)
+	c.lock.Lock()
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	c.lock.Unlock()
+	if ok {
+		return username, nil
+	}
+
+	if c.refresh() == nil {
+		if username, ok := c.cached(playerID); ok {
+			return username, nil
+		}
+	}
+
+	return c.lookups.do(playerID, func() (string, error) {
+		username, err := c.fetchOne(playerID)
+		if err != nil {
+			return "", err
+		}
+		c.lock.Lock()
+		c.usernames.put(playerID, username)
+		c.lock.Unlock()
+		return username, nil
+	})
+}
+
+func (c *usernameCache) cached(playerID string) (string, bool) {
+	// Lookups reorder the LRU list, so a write lock is required.
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.usernames.get(playerID)
+}
+
+// refresh fetches the whole player map and merges it into the cache.
+func (c *usernameCache) refresh() error {
+	_, err := c.refreshes.do("", func() (map[string]string, error) {
+		players, err := c.fetchAll()
+		if err != nil {
+			return nil, err
+		}
+		c.lock.Lock()
+		c.players = make(map[string]struct{}, len(players))
+		for id, username := range players {
+			c.usernames.put(id, username)
+			c.players[id] = struct{}{}
+		}
+		close(c.changed)
+		c.changed = make(chan struct{})
+		var changes []RosterEvent
+		onRoster := c.onRoster
+		if onRoster != nil {
+			changes = c.rosterChanges(players)
+		}
+		c.lock.Unlock()
+
+		for _, change := range changes {
+			onRoster(change)
+		}
+		return players, nil
+	})
+	return err
+}
+
+// rosterChanges compares players with the roster of the last refresh and stores players as the new roster.
+func (c *usernameCache) rosterChanges(players map[string]string) []RosterEvent {
+	var changes []RosterEvent
+	roster := make(map[string]string, len(players))
+	for id, username := range players {
+		roster[id] = username
+		old, ok := c.roster[id]
+		switch {
+		case !ok:
+			changes = append(changes, RosterEvent{Kind: RosterJoin, PlayerID: id, Username: username})
+		case old != username:
+			changes = append(changes, RosterEvent{Kind: RosterRename, PlayerID: id, Username: username, OldUsername: old})
+		}
+	}
+	for id, username := range c.roster {
+		if _, ok := players[id]; !ok {
+			changes = append(changes, RosterEvent{Kind: RosterLeave, PlayerID: id, Username: username})
+		}
+	}
+	c.roster = roster
+	return changes
+}
+
+// watchRoster makes every refresh report the changes of the player list to fn.
+func (c *usernameCache) watchRoster(fn func(RosterEvent)) {
+	c.lock.Lock()
+	c.roster = make(map[string]string)
+	c.onRoster = fn
+	c.lock.Unlock()
+}
+
+// playerCount returns the number of players in the game as of the last refresh
+// and a channel that is closed when the players change.
+func (c *usernameCache) playerCount() (int, <-chan struct{}) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return len(c.players), c.changed
+}
+
+// stats returns the number of cache hits, cache misses and cached usernames.
+func (c *usernameCache) stats() (hits, misses, size int) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.hits, c.misses, c.usernames.len()
+}
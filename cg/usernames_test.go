@@ -0,0 +1,88 @@
+package cg
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUsernameLookupsAreBatched(t *testing.T) {
+	var fetchAllCalls, fetchOneCalls int32
+	release := make(chan struct{})
+	cache := newUsernameCache(0, func() (map[string]string, error) {
+		atomic.AddInt32(&fetchAllCalls, 1)
+		<-release
+		return map[string]string{"a": "alice", "b": "bob", "c": "carol"}, nil
+	}, func(playerID string) (string, error) {
+		atomic.AddInt32(&fetchOneCalls, 1)
+		return "", errors.New("unexpected single lookup")
+	})
+
+	var wg sync.WaitGroup
+	results := make([]string, 30)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cache.get(string(rune('a' + i%3)))
+		}(i)
+	}
+	// The lookups miss the empty cache and wait for the same refresh.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, username := range results {
+		if expected := []string{"alice", "bob", "carol"}[i%3]; username != expected {
+			t.Errorf("lookup %d: expected %s, got %q", i, expected, username)
+		}
+	}
+	if n := atomic.LoadInt32(&fetchOneCalls); n != 0 {
+		t.Errorf("expected no single lookups, got %d", n)
+	}
+	if _, err := cache.get("a"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&fetchAllCalls); n > 3 {
+		t.Errorf("expected concurrent refreshes to be deduplicated, got %d requests for 30 lookups", n)
+	}
+}
+
+func TestUsernameLookupFallsBackToSinglePlayer(t *testing.T) {
+	var fetchOneCalls int32
+	release := make(chan struct{})
+	cache := newUsernameCache(0, func() (map[string]string, error) {
+		return map[string]string{"a": "alice"}, nil
+	}, func(playerID string) (string, error) {
+		atomic.AddInt32(&fetchOneCalls, 1)
+		<-release
+		return "left-" + playerID, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cache.get("gone")
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, username := range results {
+		if username != "left-gone" {
+			t.Errorf("lookup %d: expected left-gone, got %q", i, username)
+		}
+	}
+	if username, _ := cache.get("gone"); username != "left-gone" {
+		t.Errorf("expected the single lookup to be cached, got %q", username)
+	}
+	if n := atomic.LoadInt32(&fetchOneCalls); n > 2 {
+		t.Errorf("expected concurrent single lookups to be deduplicated, got %d requests", n)
+	}
+}
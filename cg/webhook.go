@@ -0,0 +1,222 @@
+package cg
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrWebhookQueueFull = errors.New("webhook queue is full")
+
+// WebhookSignatureHeader contains the HMAC-SHA256 signature of the request body as "sha256=<hex>"
+// if the webhook has a secret. See VerifyWebhookSignature.
+const WebhookSignatureHeader = "X-CodeGame-Signature"
+
+const webhookQueueSize = 100
+
+var webhookBackoff = ExponentialBackoff{Initial: 500 * time.Millisecond, Max: 30 * time.Second, Jitter: 0.2}
+
+// WebhookPayload is the JSON body of webhook requests.
+type WebhookPayload struct {
+	GameURL string `json:"game_url"`
+	GameID  string `json:"game_id"`
+	// Time is the time the event was received.
+	Time  time.Time `json:"time"`
+	Event Event     `json:"event"`
+}
+
+// Webhook POSTs the events of a socket to an HTTP endpoint, so that external services can react to them
+// without speaking the CodeGame protocol. Requests are sent in the background in the order the events were received.
+// The fields must not be changed after the event loop has been started.
+type Webhook struct {
+	// Secret is used to sign every request if set. See WebhookSignatureHeader.
+	Secret []byte
+	// MaxAttempts limits the delivery attempts per event. Requests failing with a network error,
+	// a 5xx status or 429 Too Many Requests are retried with exponential backoff. Defaults to 5.
+	MaxAttempts int
+	// OnError is called with events that could not be delivered. Delivery failures are reported
+	// from the delivery goroutine and dropped events from the event loop.
+	OnError func(event Event, err error)
+	// Client sends the requests. The default client only sends the headers set by the webhook,
+	// neither the client identification nor the credentials used for the game server.
+	Client *http.Client
+
+	url     string
+	socket  *Socket
+	filter  map[EventName]struct{}
+	id      CallbackID
+	payload func(event Event) ([]byte, error)
+
+	// lock guards closed and sending on queue, which is closed by Close.
+	lock   sync.Mutex
+	closed bool
+	queue  chan Event
+	done   chan struct{}
+}
+
+// NewWebhook forwards the events received by socket to url. If events are specified, only those are forwarded.
+// Events are only forwarded while the event loop of socket is running.
+// If the endpoint cannot keep up, events are dropped and reported to OnError with ErrWebhookQueueFull.
+func NewWebhook(socket *Socket, url string, events ...EventName) *Webhook {
+	w := newWebhook(socket, url, events)
+	w.payload = func(event Event) ([]byte, error) {
+		data, err := event.rawData()
+		if err != nil {
+			return nil, err
+		}
+		event.Data = data
+		t := event.ReceivedAt
+		if t.IsZero() {
+			// The event was dispatched manually.
+			t = clock.Now()
+		}
+		return codec.Marshal(WebhookPayload{
+			GameURL: socket.gameURL,
+			GameID:  socket.gameID,
+			Time:    t,
+			Event:   event,
+		})
+	}
+	return w
+}
+
+func newWebhook(socket *Socket, url string, events []EventName) *Webhook {
+	w := &Webhook{
+		MaxAttempts: 5,
+		url:         url,
+		socket:      socket,
+		filter:      make(map[EventName]struct{}, len(events)),
+		queue:       make(chan Event, webhookQueueSize),
+		done:        make(chan struct{}),
+	}
+	for _, name := range events {
+		w.filter[name] = struct{}{}
+	}
+	w.id = socket.OnAny(w.enqueue)
+	go w.deliverLoop()
+	return w
+}
+
+// Close stops forwarding events and waits until the queued events have been delivered.
+// It may be called from any goroutine.
+func (w *Webhook) Close() {
+	w.lock.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.lock.Unlock()
+	<-w.done
+}
+
+func (w *Webhook) enqueue(event Event) {
+	w.lock.Lock()
+	if w.closed {
+		w.lock.Unlock()
+		// The listener is removed here because Close may run concurrently with the event loop.
+		w.socket.RemoveCallback(w.id)
+		return
+	}
+	if len(w.filter) > 0 {
+		if _, ok := w.filter[event.Name]; !ok {
+			w.lock.Unlock()
+			return
+		}
+	}
+	queued := true
+	select {
+	case w.queue <- event:
+	default:
+		queued = false
+	}
+	w.lock.Unlock()
+	if !queued {
+		w.reportError(event, ErrWebhookQueueFull)
+	}
+}
+
+func (w *Webhook) deliverLoop() {
+	defer close(w.done)
+	for event := range w.queue {
+		err := w.deliver(event)
+		if err != nil {
+			w.reportError(event, err)
+		}
+	}
+}
+
+func (w *Webhook) deliver(event Event) error {
+	body, err := w.payload(event)
+	if err != nil {
+		return err
+	}
+	attempts := w.MaxAttempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(event, body)
+		if err == nil || !retry || attempt >= attempts {
+			return err
+		}
+		clock.Sleep(webhookBackoff.NextDelay(attempt))
+	}
+}
+
+// post sends body to the endpoint and reports whether a failed request should be retried.
+func (w *Webhook) post(event Event, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-CodeGame-Event", string(event.Name))
+	if len(w.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, signWebhook(body, w.Secret))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = outboundClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, redactError(err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
+
+func (w *Webhook) reportError(event Event, err error) {
+	if w.OnError != nil {
+		w.OnError(event, err)
+	}
+}
+
+func signWebhook(body, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature, the value of WebhookSignatureHeader, is a valid signature of body.
+// It is meant for services receiving webhook requests.
+func VerifyWebhookSignature(body []byte, signature string, secret []byte) bool {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(signWebhook(body, secret)))
+}
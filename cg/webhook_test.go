@@ -0,0 +1,144 @@
+package cg_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+type webhookReceiver struct {
+	*httptest.Server
+	lock     sync.Mutex
+	payloads []cg.WebhookPayload
+}
+
+func newWebhookReceiver(t *testing.T) *webhookReceiver {
+	r := &webhookReceiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload cg.WebhookPayload
+		if json.NewDecoder(req.Body).Decode(&payload) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.lock.Lock()
+		r.payloads = append(r.payloads, payload)
+		r.lock.Unlock()
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) received() []cg.WebhookPayload {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]cg.WebhookPayload(nil), r.payloads...)
+}
+
+func TestWebhookTime(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+	receiver := newWebhookReceiver(t)
+	webhook := cg.NewWebhook(socket, receiver.URL, "hello")
+
+	server.Emit("hello", map[string]int{"x": 1})
+	event := cgtest.ExpectEvent(t, socket, "hello", time.Second)
+	webhook.Close()
+
+	payloads := receiver.received()
+	if len(payloads) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(payloads))
+	}
+	if !payloads[0].Time.Equal(event.ReceivedAt) {
+		t.Errorf("expected time %s, got %s", event.ReceivedAt, payloads[0].Time)
+	}
+}
+
+func TestWebhookCloseWhileRunning(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+	receiver := newWebhookReceiver(t)
+	webhook := cg.NewWebhook(socket, receiver.URL)
+
+	loopDone := make(chan struct{})
+	go func() {
+		socket.RunEventLoop()
+		close(loopDone)
+	}()
+	go func() {
+		for i := 0; i < 200; i++ {
+			server.Emit("tick", i)
+		}
+	}()
+	waitFor(t, "first request", func() bool {
+		return len(receiver.received()) > 0
+	})
+	webhook.Close()
+	// Events dispatched after Close must not panic.
+	time.Sleep(50 * time.Millisecond)
+	socket.Close()
+	<-loopDone
+}
+
+// newHeaderReceiver starts a server sending the headers of every request to the returned channel.
+func newHeaderReceiver(t *testing.T) (*httptest.Server, chan http.Header) {
+	headers := make(chan http.Header, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		headers <- req.Header.Clone()
+	}))
+	t.Cleanup(server.Close)
+	return server, headers
+}
+
+func TestWebhookHeaders(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice", cg.WithDialOptions(cg.WithTokenSource(cg.StaticToken("secret"))))
+	receiver, headers := newHeaderReceiver(t)
+	webhook := cg.NewWebhook(socket, receiver.URL, "hello")
+	webhook.Secret = []byte("key")
+
+	server.Emit("hello", nil)
+	cgtest.ExpectEvent(t, socket, "hello", time.Second)
+	webhook.Close()
+
+	header := <-headers
+	for _, name := range []string{"Content-Type", "X-Codegame-Event", cg.WebhookSignatureHeader} {
+		if header.Get(name) == "" {
+			t.Errorf("expected header %s", name)
+		}
+	}
+	for _, name := range []string{"Authorization", "X-Codegame-Client"} {
+		if value := header.Get(name); value != "" {
+			t.Errorf("expected no header %s, got %q", name, value)
+		}
+	}
+}
+
+type addHeaderTransport struct{}
+
+func (addHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Custom", "yes")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWebhookClient(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := dialPlayer(t, server, "alice")
+	receiver, headers := newHeaderReceiver(t)
+	webhook := cg.NewWebhook(socket, receiver.URL, "hello")
+	webhook.Client = &http.Client{Transport: addHeaderTransport{}}
+
+	server.Emit("hello", nil)
+	cgtest.ExpectEvent(t, socket, "hello", time.Second)
+	webhook.Close()
+
+	if value := (<-headers).Get("X-Custom"); value != "yes" {
+		t.Errorf("expected the request to be sent with Client, got X-Custom %q", value)
+	}
+}
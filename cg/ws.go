@@ -0,0 +1,19 @@
+package cg
+
+import (
+	"io"
+	"time"
+)
+
+// wsConnection is the subset of *websocket.Conn used by Socket and DebugSocket.
+// It allows using a browser-compatible implementation when compiling for js/wasm.
+type wsConnection interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	NextReader() (messageType int, r io.Reader, err error)
+	WriteMessage(messageType int, data []byte) error
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+	SetPongHandler(h func(appData string) error)
+	Close() error
+}
@@ -0,0 +1,218 @@
+//go:build cg_coderws
+
+package cg
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	coderws "github.com/coder/websocket"
+	"github.com/gorilla/websocket"
+)
+
+// coderConn implements wsConnection using github.com/coder/websocket.
+// It is used instead of gorilla/websocket when building with the cg_coderws tag.
+// Deadlines are translated to contexts and errors to their gorilla/websocket equivalents.
+type coderConn struct {
+	conn *coderws.Conn
+
+	lock          sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	pongHandler   func(appData string) error
+	closed        bool
+	// cancelRead releases the context of the reader returned by the last call to NextReader.
+	// readTimer cancels it when the read deadline expires, which sets readExpired.
+	// Moving the deadline rearms the timer, so that pongs can extend a pending read like with gorilla/websocket.
+	cancelRead  context.CancelFunc
+	readTimer   *time.Timer
+	readExpired bool
+}
+
+func newCoderConn(conn *coderws.Conn) *coderConn {
+	// Message sizes are not limited by gorilla/websocket either.
+	conn.SetReadLimit(-1)
+	return &coderConn{conn: conn}
+}
+
+func deadlineContext(deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), deadline)
+}
+
+func (c *coderConn) ReadMessage() (int, []byte, error) {
+	c.lock.Lock()
+	ctx, cancel := deadlineContext(c.readDeadline)
+	c.lock.Unlock()
+	defer cancel()
+	messageType, data, err := c.conn.Read(ctx)
+	return int(messageType), data, convertCoderError(err)
+}
+
+func (c *coderConn) NextReader() (int, io.Reader, error) {
+	c.lock.Lock()
+	if c.cancelRead != nil {
+		c.cancelRead()
+	}
+	// The context must stay valid until the message has been read.
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelRead = cancel
+	c.armReadTimer()
+	c.lock.Unlock()
+	messageType, r, err := c.conn.Reader(ctx)
+	if err != nil {
+		return 0, nil, c.readError(err)
+	}
+	return int(messageType), coderReader{conn: c, r: r}, nil
+}
+
+// armReadTimer makes the current reader fail when the read deadline expires. c.lock must be held.
+func (c *coderConn) armReadTimer() {
+	if c.readTimer != nil {
+		c.readTimer.Stop()
+		c.readTimer = nil
+	}
+	if c.readDeadline.IsZero() || c.cancelRead == nil {
+		return
+	}
+	cancel := c.cancelRead
+	c.readTimer = time.AfterFunc(time.Until(c.readDeadline), func() {
+		c.lock.Lock()
+		c.readExpired = true
+		c.lock.Unlock()
+		cancel()
+	})
+}
+
+// readError converts err and reports it as an expired deadline if the reader was canceled by the read timer.
+func (c *coderConn) readError(err error) error {
+	c.lock.Lock()
+	expired := c.readExpired
+	c.lock.Unlock()
+	if expired && err != nil {
+		return fmt.Errorf("%w: %s", os.ErrDeadlineExceeded, err)
+	}
+	return convertCoderError(err)
+}
+
+func (c *coderConn) WriteMessage(messageType int, data []byte) error {
+	if messageType != websocket.TextMessage && messageType != websocket.BinaryMessage {
+		return fmt.Errorf("unsupported message type: %d", messageType)
+	}
+	c.lock.Lock()
+	ctx, cancel := deadlineContext(c.writeDeadline)
+	c.lock.Unlock()
+	defer cancel()
+	return convertCoderError(c.conn.Write(ctx, coderws.MessageType(messageType), data))
+}
+
+// WriteControl supports close and ping messages. Closing performs the complete close handshake.
+// Pings are sent in the background and the pong handler is called when the pong arrives.
+func (c *coderConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	switch messageType {
+	case websocket.CloseMessage:
+		code, reason := coderws.StatusNormalClosure, ""
+		if len(data) >= 2 {
+			code, reason = coderws.StatusCode(binary.BigEndian.Uint16(data)), string(data[2:])
+		}
+		c.lock.Lock()
+		c.closed = true
+		c.lock.Unlock()
+		return convertCoderError(c.conn.Close(code, reason))
+	case websocket.PingMessage:
+		go func() {
+			ctx, cancel := deadlineContext(deadline)
+			defer cancel()
+			if c.conn.Ping(ctx) != nil {
+				return
+			}
+			c.lock.Lock()
+			handler := c.pongHandler
+			c.lock.Unlock()
+			if handler != nil {
+				handler(string(data))
+			}
+		}()
+		return nil
+	default:
+		return fmt.Errorf("unsupported control message type: %d", messageType)
+	}
+}
+
+func (c *coderConn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.readDeadline = t
+	if !c.readExpired {
+		c.armReadTimer()
+	}
+	return nil
+}
+
+func (c *coderConn) SetWriteDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.writeDeadline = t
+	c.lock.Unlock()
+	return nil
+}
+
+func (c *coderConn) SetPongHandler(h func(appData string) error) {
+	c.lock.Lock()
+	c.pongHandler = h
+	c.lock.Unlock()
+}
+
+func (c *coderConn) Close() error {
+	c.lock.Lock()
+	closed := c.closed
+	c.closed = true
+	if c.cancelRead != nil {
+		c.cancelRead()
+	}
+	if c.readTimer != nil {
+		c.readTimer.Stop()
+	}
+	c.lock.Unlock()
+	if closed {
+		return nil
+	}
+	return c.conn.CloseNow()
+}
+
+// coderReader converts the errors of a message reader.
+type coderReader struct {
+	conn *coderConn
+	r    io.Reader
+}
+
+func (r coderReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF {
+		return n, err
+	}
+	return n, r.conn.readError(err)
+}
+
+// convertCoderError converts close errors to *websocket.CloseError and expired deadlines to os.ErrDeadlineExceeded,
+// which is what the socket expects from gorilla/websocket.
+func convertCoderError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var closeErr coderws.CloseError
+	if errors.As(err, &closeErr) {
+		return &websocket.CloseError{Code: int(closeErr.Code), Text: closeErr.Reason}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", os.ErrDeadlineExceeded, err)
+	}
+	return err
+}
@@ -0,0 +1,210 @@
+package cgload
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+const benchmarkGameID = "benchmark"
+
+// BenchmarkConfig configures a throughput benchmark. See Benchmark.
+type BenchmarkConfig struct {
+	// Events is the number of events sent to every client.
+	Events int
+	// Clients is the number of sockets connected in parallel. Values <= 0 are treated as 1.
+	Clients int
+	// Data is the data of every event. Defaults to a small object.
+	Data any
+	// EventBufferSize is passed to cg.WithEventBufferSize if > 0.
+	EventBufferSize int
+}
+
+// BenchmarkReport summarizes a throughput benchmark.
+type BenchmarkReport struct {
+	Clients int
+	// Events is the total number of events dispatched to listeners of all clients.
+	Events int
+	// Bytes is the total size of the received messages.
+	Bytes    int64
+	Duration time.Duration
+}
+
+// EventsPerSecond returns the number of events dispatched per second across all clients.
+func (r BenchmarkReport) EventsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Events) / r.Duration.Seconds()
+}
+
+func (r BenchmarkReport) String() string {
+	return fmt.Sprintf("clients: %d, events: %d, bytes: %d, duration: %s, throughput: %.0f events/s",
+		r.Clients, r.Events, r.Bytes, r.Duration, r.EventsPerSecond())
+}
+
+// Benchmark measures how many events per second the cg package can decode and dispatch.
+// It starts a local mock server that floods every connected spectator with config.Events events
+// as fast as possible. Every event is decoded by a listener. The network overhead is limited to the loopback interface,
+// so the result is dominated by the client, which makes it suitable to catch performance regressions.
+func Benchmark(config BenchmarkConfig) (BenchmarkReport, error) {
+	if config.Events <= 0 {
+		return BenchmarkReport{}, fmt.Errorf("invalid number of events: %d", config.Events)
+	}
+	if config.Clients <= 0 {
+		config.Clients = 1
+	}
+	if config.Data == nil {
+		config.Data = map[string]any{"x": 1, "y": 2, "message": "benchmark"}
+	}
+
+	msg, err := benchmarkMessage(config.Data)
+	if err != nil {
+		return BenchmarkReport{}, err
+	}
+	server := newFloodServer(msg, config.Events)
+	defer server.Close()
+
+	opts := []cg.Option{cg.WithGame(benchmarkGameID), cg.WithTLS(false)}
+	if config.EventBufferSize > 0 {
+		opts = append(opts, cg.WithEventBufferSize(config.EventBufferSize))
+	}
+
+	sockets := make([]*cg.Socket, 0, config.Clients)
+	defer func() {
+		for _, socket := range sockets {
+			socket.Close()
+		}
+	}()
+	var events int64
+	for i := 0; i < config.Clients; i++ {
+		socket, err := cg.Dial(server.URL, opts...)
+		if err != nil {
+			return BenchmarkReport{}, fmt.Errorf("failed to connect to benchmark server: %w", err)
+		}
+		socket.On("benchmark", func(event cg.Event) {
+			var data any
+			if event.UnmarshalData(&data) == nil {
+				atomic.AddInt64(&events, 1)
+			}
+		})
+		sockets = append(sockets, socket)
+	}
+
+	start := time.Now()
+	close(server.start)
+	var wg sync.WaitGroup
+	errs := make(chan error, len(sockets))
+	for _, socket := range sockets {
+		wg.Add(1)
+		go func(socket *cg.Socket) {
+			defer wg.Done()
+			errs <- socket.RunEventLoop()
+		}(socket)
+	}
+	wg.Wait()
+	duration := time.Since(start)
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return BenchmarkReport{}, fmt.Errorf("benchmark client failed: %w", err)
+		}
+	}
+
+	report := BenchmarkReport{Clients: len(sockets), Events: int(events), Duration: duration}
+	for _, socket := range sockets {
+		report.Bytes += socket.Stats().BytesReceived
+	}
+	return report, nil
+}
+
+func benchmarkMessage(data any) (*websocket.PreparedMessage, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := json.Marshal(cg.Event{Name: "benchmark", Data: encoded})
+	if err != nil {
+		return nil, err
+	}
+	return websocket.NewPreparedMessage(websocket.TextMessage, msg)
+}
+
+type floodServer struct {
+	*httptest.Server
+	// start is closed when the server should start sending events.
+	start  chan struct{}
+	done   chan struct{}
+	msg    *websocket.PreparedMessage
+	events int
+}
+
+// newFloodServer starts a server that implements just enough of the CodeGame API to spectate benchmarkGameID.
+// Every spectator receives msg events times followed by a normal close.
+func newFloodServer(msg *websocket.PreparedMessage, events int) *floodServer {
+	s := &floodServer{
+		start:  make(chan struct{}),
+		done:   make(chan struct{}),
+		msg:    msg,
+		events: events,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Close stops clients waiting for the start and shuts down the server.
+func (s *floodServer) Close() {
+	close(s.done)
+	s.Server.Close()
+}
+
+func (s *floodServer) handle(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/api/info":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(cg.ServerInfo{Name: "cgload", CGVersion: cg.CGVersion, Version: "0.0.0"})
+	case "/api/games/" + benchmarkGameID + "/players":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	case "/api/games/" + benchmarkGameID + "/spectate":
+		s.flood(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *floodServer) flood(w http.ResponseWriter, r *http.Request) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	select {
+	case <-s.start:
+	case <-s.done:
+		return
+	}
+	for i := 0; i < s.events; i++ {
+		if conn.WritePreparedMessage(s.msg) != nil {
+			return
+		}
+	}
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	// Wait for the client to acknowledge the close.
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
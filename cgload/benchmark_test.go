@@ -0,0 +1,43 @@
+package cgload_test
+
+import (
+	"testing"
+
+	"github.com/code-game-project/go-client/cgload"
+)
+
+func TestBenchmark(t *testing.T) {
+	report, err := cgload.Benchmark(cgload.BenchmarkConfig{Events: 200, Clients: 3, EventBufferSize: 16})
+	if err != nil {
+		t.Fatalf("Benchmark failed: %s", err)
+	}
+	if report.Clients != 3 {
+		t.Errorf("expected 3 clients, got %d", report.Clients)
+	}
+	if report.Events != 600 {
+		t.Errorf("expected every client to dispatch 200 events, got %d in total", report.Events)
+	}
+	if report.Bytes < int64(report.Events) {
+		t.Errorf("expected the received bytes to be counted, got %d", report.Bytes)
+	}
+	if report.Duration <= 0 || report.EventsPerSecond() <= 0 {
+		t.Errorf("expected a positive throughput, got %s", report)
+	}
+}
+
+func TestBenchmarkCustomData(t *testing.T) {
+	report, err := cgload.Benchmark(cgload.BenchmarkConfig{Events: 10, Data: "small"})
+	if err != nil {
+		t.Fatalf("Benchmark failed: %s", err)
+	}
+	if report.Clients != 1 || report.Events != 10 {
+		t.Errorf("expected 10 events for the default single client, got %s", report)
+	}
+}
+
+func TestBenchmarkInvalidEvents(t *testing.T) {
+	_, err := cgload.Benchmark(cgload.BenchmarkConfig{})
+	if err == nil {
+		t.Fatal("expected an error for 0 events")
+	}
+}
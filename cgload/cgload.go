@@ -0,0 +1,215 @@
+/*
+Package cgload implements a load-test harness that connects many simulated clients to a CodeGame server.
+*/
+package cgload
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+// Step is a single entry of a command script.
+type Step struct {
+	// Delay is waited before sending the command.
+	Delay   time.Duration
+	Command cg.CommandName
+	Data    any
+	// Await is the event whose arrival after sending the command is measured as latency.
+	// No latency is recorded if Await is empty.
+	Await cg.EventName
+}
+
+type Config struct {
+	GameURL string
+	// GameID is the game to join. A new game is created if GameID is empty.
+	GameID     string
+	JoinSecret string
+	// Clients is the number of simulated clients.
+	Clients int
+	// ConnectInterval is waited between starting two clients.
+	ConnectInterval time.Duration
+	// Script is replayed by every client.
+	Script []Step
+	// Repeat is the number of times the script is replayed. Values <= 0 are treated as 1.
+	Repeat int
+	// AwaitTimeout limits how long a client waits for an awaited event. Defaults to 5 seconds.
+	AwaitTimeout time.Duration
+}
+
+// Report summarizes a load test run.
+type Report struct {
+	Clients         int
+	Connected       int
+	ConnectFailures int
+	CommandsSent    int
+	EventsReceived  int
+	Errors          int
+	Timeouts        int
+	Duration        time.Duration
+	// Latencies contains all measured event latencies in ascending order.
+	Latencies []time.Duration
+}
+
+// ConnectSuccessRate returns the fraction of clients that connected successfully.
+func (r Report) ConnectSuccessRate() float64 {
+	if r.Clients == 0 {
+		return 0
+	}
+	return float64(r.Connected) / float64(r.Clients)
+}
+
+// Percentile returns the p-th (0-100) percentile of the measured event latencies.
+func (r Report) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	index := int(float64(len(r.Latencies)-1) * p / 100)
+	if index < 0 {
+		index = 0
+	} else if index >= len(r.Latencies) {
+		index = len(r.Latencies) - 1
+	}
+	return r.Latencies[index]
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("clients: %d, connected: %d (%.1f%%), commands: %d, events: %d, errors: %d, timeouts: %d, latency p50/p90/p99: %s/%s/%s, duration: %s",
+		r.Clients, r.Connected, r.ConnectSuccessRate()*100, r.CommandsSent, r.EventsReceived, r.Errors, r.Timeouts,
+		r.Percentile(50), r.Percentile(90), r.Percentile(99), r.Duration)
+}
+
+type collector struct {
+	lock   sync.Mutex
+	report Report
+}
+
+func (c *collector) add(fn func(r *Report)) {
+	c.lock.Lock()
+	fn(&c.report)
+	c.lock.Unlock()
+}
+
+// Run executes the load test described by config and blocks until all clients are done.
+func Run(config Config) (Report, error) {
+	if config.Clients <= 0 {
+		return Report{}, fmt.Errorf("invalid number of clients: %d", config.Clients)
+	}
+	if config.Repeat <= 0 {
+		config.Repeat = 1
+	}
+	if config.AwaitTimeout <= 0 {
+		config.AwaitTimeout = 5 * time.Second
+	}
+
+	if config.GameID == "" {
+		var err error
+		config.GameID, config.JoinSecret, err = cg.CreateGame(config.GameURL, false, false, nil)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to create game: %w", err)
+		}
+	}
+
+	c := &collector{report: Report{Clients: config.Clients}}
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < config.Clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			runClient(config, fmt.Sprintf("cgload-%d", i), c)
+		}(i)
+		time.Sleep(config.ConnectInterval)
+	}
+	wg.Wait()
+
+	c.report.Duration = time.Since(start)
+	sort.Slice(c.report.Latencies, func(i, j int) bool {
+		return c.report.Latencies[i] < c.report.Latencies[j]
+	})
+	return c.report, nil
+}
+
+func runClient(config Config, username string, c *collector) {
+	playerID, playerSecret, err := cg.JoinGame(config.GameURL, config.GameID, username, config.JoinSecret)
+	if err != nil {
+		c.add(func(r *Report) { r.ConnectFailures++ })
+		return
+	}
+
+	socket, err := cg.Connect(config.GameURL, config.GameID, playerID, playerSecret)
+	if err != nil {
+		c.add(func(r *Report) { r.ConnectFailures++ })
+		return
+	}
+	c.add(func(r *Report) { r.Connected++ })
+
+	awaited := make(map[cg.EventName]chan struct{})
+	for _, step := range config.Script {
+		if step.Await == "" || awaited[step.Await] != nil {
+			continue
+		}
+		ch := make(chan struct{}, 1)
+		awaited[step.Await] = ch
+		socket.On(step.Await, func(cg.Event) {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	loopErr := make(chan error, 1)
+	go func() {
+		loopErr <- socket.RunEventLoop()
+	}()
+
+	for i := 0; i < config.Repeat; i++ {
+		for _, step := range config.Script {
+			time.Sleep(step.Delay)
+			ch := awaited[step.Await]
+			if ch != nil {
+				select {
+				case <-ch:
+				default:
+				}
+			}
+
+			sent := time.Now()
+			err = socket.Send(step.Command, step.Data)
+			if err != nil {
+				c.add(func(r *Report) { r.Errors++ })
+				continue
+			}
+			c.add(func(r *Report) { r.CommandsSent++ })
+
+			if ch == nil {
+				continue
+			}
+			select {
+			case <-ch:
+				latency := time.Since(sent)
+				c.add(func(r *Report) { r.Latencies = append(r.Latencies, latency) })
+			case <-time.After(config.AwaitTimeout):
+				c.add(func(r *Report) { r.Timeouts++ })
+			}
+		}
+	}
+
+	socket.Close()
+	if err := <-loopErr; err != nil {
+		c.add(func(r *Report) { r.Errors++ })
+	}
+
+	stats := socket.Stats()
+	c.add(func(r *Report) {
+		for _, count := range stats.EventCounts {
+			r.EventsReceived += count
+		}
+		r.Errors += stats.DecodeErrors
+	})
+}
@@ -0,0 +1,150 @@
+package cgload_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgload"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+// echo answers every command received by server with an event of the same name sent to the player of the command.
+func echo(t *testing.T, server *cgtest.Server) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		<-stopped
+	})
+	go func() {
+		defer close(stopped)
+		answered := 0
+		for {
+			commands := server.Commands()
+			for _, cmd := range commands[answered:] {
+				server.EmitTo(cmd.PlayerID, cg.EventName(cmd.Name), nil)
+			}
+			answered = len(commands)
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+}
+
+func TestRun(t *testing.T) {
+	server := cgtest.NewServer(t)
+	echo(t, server)
+
+	report, err := cgload.Run(cgload.Config{
+		GameURL: server.URL,
+		Clients: 3,
+		Script: []cgload.Step{
+			{Command: "move", Await: "move"},
+			{Command: "chat"},
+		},
+		Repeat:       2,
+		AwaitTimeout: 2 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+
+	if report.Clients != 3 || report.Connected != 3 || report.ConnectFailures != 0 {
+		t.Errorf("expected 3 connected clients, got %s", report)
+	}
+	if report.CommandsSent != 12 {
+		t.Errorf("expected 12 commands, got %d", report.CommandsSent)
+	}
+	if report.Timeouts != 0 || report.Errors != 0 {
+		t.Errorf("expected no timeouts or errors, got %s", report)
+	}
+	if len(report.Latencies) != 6 {
+		t.Fatalf("expected a latency for every awaited command, got %d", len(report.Latencies))
+	}
+	for i := 1; i < len(report.Latencies); i++ {
+		if report.Latencies[i] < report.Latencies[i-1] {
+			t.Fatalf("expected sorted latencies, got %v", report.Latencies)
+		}
+	}
+	if report.EventsReceived < 6 {
+		t.Errorf("expected at least 6 events, got %d", report.EventsReceived)
+	}
+	// The last commands may still be in flight.
+	deadline := time.Now().Add(2 * time.Second)
+	for len(server.Commands()) < 12 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := len(server.Commands()); n != 12 {
+		t.Errorf("expected the server to receive 12 commands, got %d", n)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	server := cgtest.NewServer(t)
+
+	report, err := cgload.Run(cgload.Config{
+		GameURL:      server.URL,
+		GameID:       server.GameID,
+		Clients:      1,
+		Script:       []cgload.Step{{Command: "move", Await: "move"}},
+		AwaitTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+	if report.Timeouts != 1 || len(report.Latencies) != 0 {
+		t.Errorf("expected the unanswered command to time out, got %s", report)
+	}
+}
+
+func TestRunConnectFailures(t *testing.T) {
+	server := cgtest.NewServer(t)
+
+	report, err := cgload.Run(cgload.Config{
+		GameURL: server.URL,
+		GameID:  "unknown",
+		Clients: 2,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+	if report.Connected != 0 || report.ConnectFailures != 2 || report.ConnectSuccessRate() != 0 {
+		t.Errorf("expected all clients to fail to join the unknown game, got %s", report)
+	}
+}
+
+func TestRunInvalidClients(t *testing.T) {
+	_, err := cgload.Run(cgload.Config{GameURL: "localhost:1"})
+	if err == nil {
+		t.Fatal("expected an error for 0 clients")
+	}
+}
+
+func TestReportPercentile(t *testing.T) {
+	report := cgload.Report{}
+	for i := 1; i <= 100; i++ {
+		report.Latencies = append(report.Latencies, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{0, time.Millisecond},
+		{50, 50 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+		{150, 100 * time.Millisecond},
+	}
+	for _, test := range tests {
+		if latency := report.Percentile(test.p); latency != test.expected {
+			t.Errorf("p%v: expected %s, got %s", test.p, test.expected, latency)
+		}
+	}
+	if latency := (cgload.Report{}).Percentile(50); latency != 0 {
+		t.Errorf("expected 0 without latencies, got %s", latency)
+	}
+}
@@ -0,0 +1,279 @@
+/*
+Package cgscaffold generates Go bot projects for CodeGame servers.
+
+A generated project contains typed wrappers for all events and commands of the game,
+a main.go that connects using cg.ConnectFromEnv and runs the event loop, a go.mod and a .codegame.json file.
+*/
+package cgscaffold
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+var ErrFileExists = errors.New("file already exists")
+
+type Options struct {
+	GameURL string
+	// Dir is the directory the project is generated in. It is created if it does not exist.
+	Dir string
+	// Module is the Go module path. Defaults to the name of the game.
+	Module string
+	// ClientVersion is the version of the go-client module required in go.mod, e.g. "v0.10.0".
+	// If empty, the requirement is left to `go mod tidy`.
+	ClientVersion string
+}
+
+// Generate fetches the CGE file of the server at opts.GameURL and generates a new project in opts.Dir.
+// Existing files are never overwritten; ErrFileExists is returned instead.
+func Generate(opts Options) error {
+	def, err := cg.FetchEventsDefinition(opts.GameURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch events definition: %w", err)
+	}
+	return GenerateFromDefinition(def, opts)
+}
+
+// GenerateFromDefinition generates a new project for def in opts.Dir.
+func GenerateFromDefinition(def *cg.EventsDefinition, opts Options) error {
+	pkg := packageName(def.Name)
+	module := opts.Module
+	if module == "" {
+		module = pkg
+	}
+
+	wrappers, err := GenerateWrappers(def, pkg)
+	if err != nil {
+		return err
+	}
+	main, err := generateMain(def, module, pkg)
+	if err != nil {
+		return err
+	}
+	project, err := json.MarshalIndent(cg.ProjectInfo{
+		Name:    def.Name,
+		Type:    "client",
+		Lang:    "go",
+		GameURL: opts.GameURL,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		"go.mod":                      generateGoMod(module, opts.ClientVersion),
+		".codegame.json":              append(project, '\n'),
+		"main.go":                     main,
+		filepath.Join(pkg, pkg+".go"): wrappers,
+	}
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(opts.Dir, name)); err == nil {
+			return fmt.Errorf("%w: %s", ErrFileExists, name)
+		}
+	}
+	for name, content := range files {
+		path := filepath.Join(opts.Dir, name)
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(path, content, 0o644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generateGoMod(module, clientVersion string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n\ngo 1.18\n", module)
+	if clientVersion != "" {
+		fmt.Fprintf(&b, "\nrequire github.com/code-game-project/go-client %s\n", clientVersion)
+	}
+	return []byte(b.String())
+}
+
+func generateMain(def *cg.EventsDefinition, module, pkg string) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, `package main
+
+import (
+	"log"
+
+	"github.com/code-game-project/go-client/cg"
+
+	%q
+)
+
+func main() {
+	// The connection is configured with the CG_* environment variables. See cg.ConnectFromEnv.
+	socket, err := cg.ConnectFromEnv()
+	if err != nil {
+		log.Fatalf("error: %%s", err)
+	}
+`, module+"/"+pkg)
+	for _, event := range def.Events {
+		name := exportedName(event.Name)
+		fmt.Fprintf(&b, `
+	%s.On%sEvent(socket, func(data %s.%sEventData) {
+		// TODO: handle the %s event
+	})
+`, pkg, name, pkg, name, event.Name)
+	}
+	b.WriteString(`
+	err = cg.RunUntilInterrupt(socket)
+	if err != nil {
+		log.Fatalf("error: %s", err)
+	}
+}
+`)
+	return format.Source([]byte(b.String()))
+}
+
+// GenerateWrappers returns the source of a Go file in package pkg containing typed wrappers
+// for the events, commands, types, enums and config of def.
+func GenerateWrappers(def *cg.EventsDefinition, pkg string) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by cgscaffold from the events definition of %s. DO NOT EDIT.\n\n", def.Name)
+	fmt.Fprintf(&b, "package %s\n\nimport \"github.com/code-game-project/go-client/cg\"\n", pkg)
+
+	if def.Config != nil {
+		writeStruct(&b, "GameConfig", def.Config.Doc, def.Config.Properties)
+	}
+
+	for _, event := range def.Events {
+		name := exportedName(event.Name)
+		fmt.Fprintf(&b, "\n%sconst %sEvent cg.EventName = %q\n", docComment(event.Doc), name, event.Name)
+		writeStruct(&b, name+"EventData", "", event.Properties)
+		fmt.Fprintf(&b, `
+// On%[1]sEvent registers callback for the %[2]s event.
+func On%[1]sEvent(socket *cg.Socket, callback func(data %[1]sEventData)) cg.CallbackID {
+	return socket.On(%[1]sEvent, func(event cg.Event) {
+		if data, ok := cg.DecodedDataAs[%[1]sEventData](event); ok {
+			callback(data)
+		}
+	})
+}
+`, name, event.Name)
+	}
+
+	for _, command := range def.Commands {
+		name := exportedName(command.Name)
+		fmt.Fprintf(&b, "\n%sconst %sCmd cg.CommandName = %q\n", docComment(command.Doc), name, command.Name)
+		writeStruct(&b, name+"CmdData", "", command.Properties)
+		fmt.Fprintf(&b, `
+// Send%[1]sCmd sends the %[2]s command.
+func Send%[1]sCmd(socket *cg.Socket, data %[1]sCmdData) error {
+	return cg.SendCommand(socket, %[1]sCmd, data)
+}
+`, name, command.Name)
+	}
+
+	for _, typ := range def.Types {
+		writeStruct(&b, exportedName(typ.Name), typ.Doc, typ.Properties)
+	}
+
+	for _, enum := range def.Enums {
+		name := exportedName(enum.Name)
+		fmt.Fprintf(&b, "\n%stype %s string\n\nconst (\n", docComment(enum.Doc), name)
+		for _, value := range enum.Values {
+			fmt.Fprintf(&b, "%s%s%s %s = %q\n", docComment(value.Doc), name, exportedName(value.Name), name, value.Name)
+		}
+		b.WriteString(")\n")
+	}
+
+	if len(def.Events) > 0 {
+		b.WriteString("\nfunc init() {\n")
+		for _, event := range def.Events {
+			name := exportedName(event.Name)
+			fmt.Fprintf(&b, "cg.RegisterEventType[%sEventData](%sEvent)\n", name, name)
+		}
+		b.WriteString("}\n")
+	}
+
+	return format.Source([]byte(b.String()))
+}
+
+func writeStruct(b *strings.Builder, name, doc string, properties []cg.PropertyDefinition) {
+	fmt.Fprintf(b, "\n%stype %s struct {\n", docComment(doc), name)
+	for _, property := range properties {
+		fmt.Fprintf(b, "%s%s %s `json:\"%s\"`\n", docComment(property.Doc), exportedName(property.Name), goType(property.Type), property.Name)
+	}
+	b.WriteString("}\n")
+}
+
+// goType converts a CGE type to a Go type.
+func goType(cgeType string) string {
+	switch {
+	case strings.HasPrefix(cgeType, "list<"):
+		return "[]" + goType(cgeType[len("list<"):len(cgeType)-1])
+	case strings.HasPrefix(cgeType, "map<"):
+		return "map[string]" + goType(cgeType[len("map<"):len(cgeType)-1])
+	}
+	switch cgeType {
+	case "string", "bool", "int32", "int64", "float32", "float64":
+		return cgeType
+	case "int":
+		return "int"
+	case "float":
+		return "float64"
+	case "any":
+		return "any"
+	default:
+		return exportedName(cgeType)
+	}
+}
+
+func docComment(doc string) string {
+	if doc == "" {
+		return ""
+	}
+	return "// " + strings.ReplaceAll(strings.TrimSpace(doc), "\n", "\n// ") + "\n"
+}
+
+// exportedName converts a snake_case or kebab-case name to PascalCase.
+func exportedName(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '-' || r == ' ' || r == '.' {
+			upper = true
+			continue
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteByte('X')
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// packageName converts the name of a game to a valid package name.
+func packageName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (b.Len() > 0 && r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "game"
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package cgscaffold_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgscaffold"
+)
+
+const testCGE = `name test_game
+version 0.1
+
+config {
+	width: int
+}
+
+// Sent when a player moved.
+event player_moved {
+	player_id: string,
+	pos: type position {
+		x: float,
+		y: float
+	},
+	path: list<position>,
+	direction: enum direction { north, south }
+}
+
+command move {
+	direction: direction
+}
+`
+
+func parseTestCGE(t *testing.T) *cg.EventsDefinition {
+	t.Helper()
+	def, err := cg.ParseCGE(testCGE)
+	if err != nil {
+		t.Fatalf("failed to parse CGE: %s", err)
+	}
+	return def
+}
+
+func TestGenerateWrappers(t *testing.T) {
+	source, err := cgscaffold.GenerateWrappers(parseTestCGE(t), "testgame")
+	if err != nil {
+		t.Fatalf("GenerateWrappers failed: %s", err)
+	}
+	// Whitespace is normalized because gofmt aligns struct fields.
+	normalized := strings.Join(strings.Fields(string(source)), " ")
+	for _, expected := range []string{
+		"package testgame",
+		"type GameConfig struct",
+		`const PlayerMovedEvent cg.EventName = "player_moved"`,
+		"PlayerId string `json:\"player_id\"`",
+		"Path []Position `json:\"path\"`",
+		"func OnPlayerMovedEvent(socket *cg.Socket, callback func(data PlayerMovedEventData)) cg.CallbackID",
+		"func SendMoveCmd(socket *cg.Socket, data MoveCmdData) error",
+		`DirectionNorth Direction = "north"`,
+		"// Sent when a player moved.",
+		"cg.RegisterEventType[PlayerMovedEventData](PlayerMovedEvent)",
+	} {
+		if !strings.Contains(normalized, expected) {
+			t.Errorf("expected the wrappers to contain %q:\n%s", expected, source)
+		}
+	}
+}
+
+func TestGenerateFromDefinition(t *testing.T) {
+	dir := t.TempDir()
+	opts := cgscaffold.Options{GameURL: "games.example.com", Dir: dir, Module: "example.com/bot"}
+	err := cgscaffold.GenerateFromDefinition(parseTestCGE(t), opts)
+	if err != nil {
+		t.Fatalf("GenerateFromDefinition failed: %s", err)
+	}
+
+	for _, name := range []string{"go.mod", ".codegame.json", "main.go", filepath.Join("testgame", "testgame.go")} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be generated: %s", name, err)
+		}
+	}
+	project, err := cg.LoadProjectInfo(filepath.Join(dir, ".codegame.json"))
+	if err != nil {
+		t.Fatalf("failed to load the generated project info: %s", err)
+	}
+	if project.Name != "test_game" || project.GameURL != "games.example.com" || project.Lang != "go" {
+		t.Errorf("unexpected project info: %+v", project)
+	}
+
+	err = cgscaffold.GenerateFromDefinition(parseTestCGE(t), opts)
+	if !errors.Is(err, cgscaffold.ErrFileExists) {
+		t.Errorf("expected ErrFileExists when generating into the same directory, got %v", err)
+	}
+}
+
+func TestGeneratedProjectBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building the generated project is slow")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	err = cgscaffold.GenerateFromDefinition(parseTestCGE(t), cgscaffold.Options{GameURL: "games.example.com", Dir: dir, Module: "example.com/bot"})
+	if err != nil {
+		t.Fatalf("GenerateFromDefinition failed: %s", err)
+	}
+
+	// Build against this checkout without network access.
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goMod = append(goMod, "\nrequire github.com/code-game-project/go-client v0.0.0\n\nreplace github.com/code-game-project/go-client => "+root+"\n"...)
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.WriteFile(filepath.Join(dir, "go.mod"), goMod, 0o644) != nil || os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0o644) != nil {
+		t.Fatal("failed to prepare the generated module")
+	}
+
+	cmd := exec.Command(goBin, "build", "-mod=mod", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=", "GOPROXY=off", "GOWORK=off")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to build the generated project: %s\n%s", err, output)
+	}
+}
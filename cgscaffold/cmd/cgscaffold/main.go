@@ -0,0 +1,50 @@
+// Command cgscaffold generates Go bot projects for CodeGame servers.
+//
+// Usage:
+//
+//	cgscaffold new [-module path] [-version v] <game-url> [dir]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/code-game-project/go-client/cgscaffold"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cgscaffold new [-module path] [-version v] <game-url> [dir]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "new" {
+		usage()
+	}
+
+	flags := flag.NewFlagSet("new", flag.ExitOnError)
+	flags.Usage = usage
+	module := flags.String("module", "", "Go module path (default: the name of the game)")
+	version := flags.String("version", "", "required version of the go-client module (default: resolved by go mod tidy)")
+	flags.Parse(os.Args[2:])
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		usage()
+	}
+	dir := "."
+	if flags.NArg() == 2 {
+		dir = flags.Arg(1)
+	}
+
+	err := cgscaffold.Generate(cgscaffold.Options{
+		GameURL:       flags.Arg(0),
+		Dir:           dir,
+		Module:        *module,
+		ClientVersion: *version,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Project created. Run `go mod tidy` to download the dependencies.")
+}
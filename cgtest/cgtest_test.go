@@ -0,0 +1,146 @@
+package cgtest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+)
+
+type ping struct {
+	N int `json:"n"`
+}
+
+// pongBot answers every ping with a pong carrying the same number.
+func pongBot(t *testing.T, socket *cg.Socket) {
+	socket.On("ping", func(event cg.Event) {
+		var data ping
+		err := event.UnmarshalData(&data)
+		if err != nil {
+			t.Errorf("failed to decode ping: %s", err)
+			return
+		}
+		socket.Send("pong", data)
+	})
+}
+
+func TestExpectEvent(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := server.Connect(t, "alice")
+	var dispatched []cg.EventName
+	socket.OnAny(func(event cg.Event) {
+		dispatched = append(dispatched, event.Name)
+	})
+
+	server.Emit("first", nil)
+	server.Emit("second", ping{N: 2})
+	event := cgtest.ExpectEvent(t, socket, "second", time.Second)
+
+	var data ping
+	err := event.UnmarshalData(&data)
+	if err != nil || data.N != 2 {
+		t.Errorf("expected the data of the second event, got %+v (%v)", data, err)
+	}
+	if len(dispatched) != 2 || dispatched[0] != "first" || dispatched[1] != "second" {
+		t.Errorf("expected the skipped events to be dispatched as well, got %v", dispatched)
+	}
+	cgtest.ExpectNoEvent(t, socket, "first", 20*time.Millisecond)
+}
+
+func TestPump(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := server.Connect(t, "alice")
+	count := 0
+	socket.On("tick", func(cg.Event) {
+		count++
+	})
+
+	for i := 0; i < 3; i++ {
+		server.Emit("tick", i)
+	}
+	server.Emit("done", nil)
+	deadline := time.Now().Add(time.Second)
+	for socket.QueueLen() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := cgtest.Pump(t, socket); n != 4 {
+		t.Errorf("expected 4 pumped events, got %d", n)
+	}
+	if count != 3 {
+		t.Errorf("expected the listener to be triggered 3 times, got %d", count)
+	}
+	if n := cgtest.Pump(t, socket); n != 0 {
+		t.Errorf("expected an empty queue, got %d events", n)
+	}
+}
+
+func TestExpectCommand(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := server.Connect(t, "alice")
+	pongBot(t, socket)
+
+	server.Emit("ping", ping{N: 1})
+	server.Emit("ping", ping{N: 2})
+	cgtest.ExpectEvent(t, socket, "ping", time.Second)
+	cgtest.ExpectEvent(t, socket, "ping", time.Second)
+
+	cmd := cgtest.ExpectCommand(t, server, "pong", cgtest.DataEquals(ping{N: 2}))
+	if cmd.PlayerID != socket.PlayerID() {
+		t.Errorf("expected the command of %s, got %s", socket.PlayerID(), cmd.PlayerID)
+	}
+	// Commands received before the call are matched as well.
+	cgtest.ExpectCommand(t, server, "pong", nil)
+	if n := len(server.Commands()); n != 2 {
+		t.Errorf("expected 2 commands, got %d", n)
+	}
+}
+
+func TestRunScenarioFile(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := server.Connect(t, "alice")
+	pongBot(t, socket)
+
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	err := os.WriteFile(path, []byte(`{
+		"name": "answers pings",
+		"steps": [
+			{"emit": {"name": "ping", "data": {"n": 1}}},
+			{"expect": {"name": "pong", "data": {"n": 1}}},
+			{"emit": {"name": "ping", "data": {"n": 2}, "to": "`+socket.PlayerID()+`"}},
+			{"expect": {"name": "pong"}}
+		]
+	}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cgtest.RunScenarioFile(t, server, socket, path)
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := cgtest.NewFakeClock(start)
+
+	woke := make(chan time.Time)
+	go func() {
+		woke <- <-clock.After(time.Minute)
+	}()
+	clock.BlockUntil(1)
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-woke:
+		t.Fatal("expected the timer not to fire before its deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(30 * time.Second)
+	if now := <-woke; !now.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected the timer to fire at %s, got %s", start.Add(time.Minute), now)
+	}
+	if clock.Waiters() != 0 {
+		t.Errorf("expected no pending timers, got %d", clock.Waiters())
+	}
+}
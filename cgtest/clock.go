@@ -0,0 +1,94 @@
+package cgtest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+var _ cg.Clock = (*FakeClock)(nil)
+
+type fakeTimer struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// FakeClock is a cg.Clock whose time only advances when Advance is called.
+// Use it with cg.SetClock to test timeouts and backoff without real sleeps.
+type FakeClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	timers  []fakeTimer
+	changed chan struct{}
+}
+
+// NewFakeClock creates a fake clock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, fakeTimer{deadline: c.now.Add(d), ch: ch})
+	close(c.changed)
+	c.changed = make(chan struct{})
+	return ch
+}
+
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Advance moves the time forward and fires all timers that expire in the process.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	remaining := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			remaining = append(remaining, timer)
+			continue
+		}
+		timer.ch <- c.now
+	}
+	c.timers = remaining
+}
+
+// Waiters returns the number of pending timers.
+func (c *FakeClock) Waiters() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil blocks until at least n timers are pending.
+// It is useful for waiting until the code under test has started sleeping before calling Advance.
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.lock.Lock()
+		count := len(c.timers)
+		changed := c.changed
+		c.lock.Unlock()
+		if count >= n {
+			return
+		}
+		<-changed
+	}
+}
@@ -0,0 +1,105 @@
+package cgtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+// pollInterval is the time between two checks of the event queue of a socket.
+const pollInterval = time.Millisecond
+
+// ExpectEvent dispatches the events of socket until an event with the specified name is received and returns it.
+// Registered event listeners are triggered for all dispatched events in the calling goroutine.
+// The test fails if no such event is received within the timeout.
+func ExpectEvent(t testing.TB, socket *cg.Socket, name cg.EventName, within time.Duration) cg.Event {
+	t.Helper()
+	deadline := time.Now().Add(within)
+	for {
+		event, ok, err := socket.NextEvent()
+		if err != nil {
+			t.Fatalf("expected event '%s', got error: %s", name, err)
+		}
+		if ok {
+			if event.Name == name {
+				return event
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected event '%s' within %s", name, within)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// ExpectNoEvent dispatches the events of socket for the specified duration and fails the test if an event with the specified name is received.
+func ExpectNoEvent(t testing.TB, socket *cg.Socket, name cg.EventName, within time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(within)
+	for time.Now().Before(deadline) {
+		event, ok, err := socket.NextEvent()
+		if err == cg.ErrClosed {
+			return
+		}
+		if err != nil {
+			t.Fatalf("expected no event '%s', got error: %s", name, err)
+		}
+		if ok {
+			if event.Name == name {
+				t.Fatalf("unexpected event '%s'", name)
+			}
+			continue
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// Pump dispatches all events currently queued in socket and returns their number.
+// It allows driving event listeners deterministically from the test goroutine.
+func Pump(t testing.TB, socket *cg.Socket) int {
+	t.Helper()
+	count := 0
+	for {
+		_, ok, err := socket.NextEvent()
+		if err == cg.ErrClosed {
+			return count
+		}
+		if err != nil {
+			t.Fatalf("failed to pump events: %s", err)
+		}
+		if !ok {
+			return count
+		}
+		count++
+	}
+}
+
+// ExpectCommand waits until the server receives a command with the specified name for which matcher returns true
+// and returns it. A nil matcher matches all commands. Commands received before the call are considered as well.
+// The test fails if no such command is received within server.Timeout.
+func ExpectCommand(t testing.TB, server *Server, name cg.CommandName, matcher func(cmd ReceivedCommand) bool) ReceivedCommand {
+	t.Helper()
+	deadline := time.Now().Add(server.Timeout)
+	index := 0
+	for {
+		commands, ok := server.waitForCommand(index, deadline)
+		if !ok {
+			t.Fatalf("expected command '%s' within %s", name, server.Timeout)
+		}
+		for _, cmd := range commands {
+			if cmd.Name == name && (matcher == nil || matcher(cmd)) {
+				return cmd
+			}
+		}
+		index += len(commands)
+	}
+}
+
+// DataEquals returns a matcher that compares the command data with the JSON encoding of expected.
+func DataEquals(expected any) func(cmd ReceivedCommand) bool {
+	return func(cmd ReceivedCommand) bool {
+		return jsonEqual(cmd.Data, expected)
+	}
+}
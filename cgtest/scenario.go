@@ -0,0 +1,124 @@
+package cgtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+// Scenario is a declarative protocol-level test.
+// Each step either emits an event from the server or expects a command from the client.
+//
+// Example scenario file:
+//
+//	{
+//	  "name": "answers ping",
+//	  "steps": [
+//	    {"emit": {"name": "ping", "data": {"n": 1}}},
+//	    {"expect": {"name": "pong", "data": {"n": 1}}}
+//	  ]
+//	}
+type Scenario struct {
+	Name  string         `json:"name"`
+	Steps []ScenarioStep `json:"steps"`
+}
+
+type ScenarioStep struct {
+	Emit   *ScenarioEvent   `json:"emit,omitempty"`
+	Expect *ScenarioCommand `json:"expect,omitempty"`
+}
+
+// ScenarioEvent is emitted to all clients or only to the clients of To.
+type ScenarioEvent struct {
+	Name cg.EventName    `json:"name"`
+	Data json.RawMessage `json:"data,omitempty"`
+	To   string          `json:"to,omitempty"`
+}
+
+// ScenarioCommand matches the next command with Name. If Data is set, the command data must be equal.
+type ScenarioCommand struct {
+	Name cg.CommandName  `json:"name"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// LoadScenario reads a scenario from a JSON file.
+func LoadScenario(path string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	var scenario Scenario
+	err = json.Unmarshal(data, &scenario)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// RunScenarioFile loads the scenario at path and runs it.
+func RunScenarioFile(t testing.TB, server *Server, socket *cg.Socket, path string) {
+	t.Helper()
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	RunScenario(t, server, socket, scenario)
+}
+
+// RunScenario drives socket through the steps of scenario.
+// Emitted events are dispatched to the listeners of socket in the calling goroutine.
+// Expected commands have to be sent in the order of the scenario; other commands in between are ignored.
+func RunScenario(t testing.TB, server *Server, socket *cg.Socket, scenario Scenario) {
+	t.Helper()
+	next := len(server.Commands())
+	for i, step := range scenario.Steps {
+		switch {
+		case step.Emit != nil:
+			var data any = step.Emit.Data
+			if len(step.Emit.Data) == 0 {
+				data = nil
+			}
+			var err error
+			if step.Emit.To != "" {
+				err = server.EmitTo(step.Emit.To, step.Emit.Name, data)
+			} else {
+				err = server.Emit(step.Emit.Name, data)
+			}
+			if err != nil {
+				t.Fatalf("%s: step %d: failed to emit '%s': %s", scenario.Name, i, step.Emit.Name, err)
+			}
+			if step.Emit.To == "" || step.Emit.To == socket.PlayerID() {
+				ExpectEvent(t, socket, step.Emit.Name, server.Timeout)
+			}
+		case step.Expect != nil:
+			next = expectNextCommand(t, server, *step.Expect, next, fmt.Sprintf("%s: step %d", scenario.Name, i))
+		default:
+			t.Fatalf("%s: step %d: empty step", scenario.Name, i)
+		}
+	}
+}
+
+func expectNextCommand(t testing.TB, server *Server, expected ScenarioCommand, next int, step string) int {
+	t.Helper()
+	deadline := time.Now().Add(server.Timeout)
+	for {
+		commands, ok := server.waitForCommand(next, deadline)
+		if !ok {
+			t.Fatalf("%s: expected command '%s' within %s", step, expected.Name, server.Timeout)
+		}
+		for _, cmd := range commands {
+			next++
+			if cmd.Name != expected.Name {
+				continue
+			}
+			if len(expected.Data) > 0 && !jsonEqual(cmd.Data, expected.Data) {
+				t.Fatalf("%s: command '%s' has unexpected data:\nexpected: %s\nactual:   %s", step, expected.Name, expected.Data, cmd.Data)
+			}
+			return next
+		}
+	}
+}
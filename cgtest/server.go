@@ -0,0 +1,329 @@
+/*
+Package cgtest provides a mock CodeGame server and assertion helpers for testing bots.
+*/
+package cgtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+// ReceivedCommand is a command received by the mock server.
+type ReceivedCommand struct {
+	PlayerID string
+	cg.Command
+}
+
+type player struct {
+	username string
+	secret   string
+}
+
+type client struct {
+	lock     sync.Mutex
+	conn     *websocket.Conn
+	playerID string
+}
+
+// Server is a mock CodeGame server serving a single game.
+type Server struct {
+	*httptest.Server
+	GameID string
+	// Timeout is used by ExpectCommand. Default: 1s.
+	Timeout time.Duration
+
+	lock     sync.Mutex
+	players  map[string]player
+	clients  map[*client]struct{}
+	commands []ReceivedCommand
+	changed  chan struct{}
+	next     int
+}
+
+// NewServer starts a mock server that is shut down when the test finishes.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		GameID:  "test_game",
+		Timeout: time.Second,
+		players: make(map[string]player),
+		clients: make(map[*client]struct{}),
+		changed: make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// AddPlayer creates a new player and returns its credentials.
+func (s *Server) AddPlayer(username string) (playerID, playerSecret string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.addPlayer(username)
+}
+
+func (s *Server) addPlayer(username string) (string, string) {
+	s.next++
+	playerID := "player_" + strconv.Itoa(s.next)
+	secret := randomSecret()
+	s.players[playerID] = player{username: username, secret: secret}
+	return playerID, secret
+}
+
+// Connect adds a new player and connects a socket to it.
+func (s *Server) Connect(t testing.TB, username string) *cg.Socket {
+	t.Helper()
+	playerID, secret := s.AddPlayer(username)
+	socket, err := cg.Connect(s.URL, s.GameID, playerID, secret)
+	if err != nil {
+		t.Fatalf("failed to connect to mock server: %s", err)
+	}
+	t.Cleanup(func() {
+		socket.Close()
+	})
+	return socket
+}
+
+// Emit sends an event to all connected clients.
+func (s *Server) Emit(name cg.EventName, data any) error {
+	return s.emit(name, data, func(*client) bool { return true })
+}
+
+// EmitTo sends an event to all clients of playerID.
+func (s *Server) EmitTo(playerID string, name cg.EventName, data any) error {
+	return s.emit(name, data, func(c *client) bool { return c.playerID == playerID })
+}
+
+func (s *Server) emit(name cg.EventName, data any, filter func(c *client) bool) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	msg, err := json.Marshal(cg.Event{Name: name, Data: encoded})
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		if filter(c) {
+			clients = append(clients, c)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, c := range clients {
+		c.lock.Lock()
+		err = c.conn.WriteMessage(websocket.TextMessage, msg)
+		c.lock.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Commands returns all commands received so far.
+func (s *Server) Commands() []ReceivedCommand {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]ReceivedCommand(nil), s.commands...)
+}
+
+// DisconnectAll closes the connections of all clients normally.
+func (s *Server) DisconnectAll() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for c := range s.clients {
+		c.lock.Lock()
+		c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+		c.conn.Close()
+		c.lock.Unlock()
+		delete(s.clients, c)
+	}
+}
+
+// waitForCommand blocks until a command after index start is received or the timeout expires.
+func (s *Server) waitForCommand(start int, deadline time.Time) ([]ReceivedCommand, bool) {
+	for {
+		s.lock.Lock()
+		commands := append([]ReceivedCommand(nil), s.commands[start:]...)
+		changed := s.changed
+		s.lock.Unlock()
+		if len(commands) > 0 {
+			return commands, true
+		}
+		select {
+		case <-changed:
+		case <-time.After(time.Until(deadline)):
+			return nil, false
+		}
+	}
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "api" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "info":
+		writeJSON(w, http.StatusOK, cg.ServerInfo{Name: "cgtest", CGVersion: cg.CGVersion, Version: "0.0.0"})
+	case len(parts) == 2 && parts[1] == "debug":
+		s.upgrade(w, r, "")
+	case len(parts) == 2 && parts[1] == "games" && r.Method == http.MethodPost:
+		writeJSON(w, http.StatusCreated, map[string]string{"game_id": s.GameID})
+	case len(parts) < 3 || parts[2] != s.GameID:
+		http.Error(w, "game not found", http.StatusNotFound)
+	case len(parts) == 3:
+		writeJSON(w, http.StatusOK, map[string]any{"config": map[string]any{}})
+	case len(parts) == 4 && parts[3] == "spectate":
+		s.upgrade(w, r, "")
+	case len(parts) == 4 && parts[3] == "players":
+		s.handlePlayers(w, r)
+	case len(parts) >= 5 && parts[3] == "players":
+		s.handlePlayer(w, r, parts[4], parts[5:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handlePlayers(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if r.Method == http.MethodPost {
+		var req struct {
+			Username string `json:"username"`
+		}
+		if json.NewDecoder(r.Body).Decode(&req) != nil || req.Username == "" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		playerID, secret := s.addPlayer(req.Username)
+		writeJSON(w, http.StatusCreated, map[string]string{"player_id": playerID, "player_secret": secret})
+		return
+	}
+	usernames := make(map[string]string, len(s.players))
+	for id, p := range s.players {
+		usernames[id] = p.username
+	}
+	writeJSON(w, http.StatusOK, usernames)
+}
+
+func (s *Server) handlePlayer(w http.ResponseWriter, r *http.Request, playerID string, rest []string) {
+	s.lock.Lock()
+	p, ok := s.players[playerID]
+	s.lock.Unlock()
+	if !ok {
+		http.Error(w, "player not found", http.StatusNotFound)
+		return
+	}
+
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodDelete:
+			if r.URL.Query().Get("player_secret") != p.secret {
+				http.Error(w, "invalid player secret", http.StatusUnauthorized)
+				return
+			}
+			s.lock.Lock()
+			delete(s.players, playerID)
+			s.lock.Unlock()
+			w.WriteHeader(http.StatusOK)
+		default:
+			writeJSON(w, http.StatusOK, map[string]string{"username": p.username})
+		}
+		return
+	}
+
+	if (rest[0] == "connect" || rest[0] == "debug") && len(rest) == 1 {
+		if r.URL.Query().Get("player_secret") != p.secret {
+			http.Error(w, "invalid player secret", http.StatusUnauthorized)
+			return
+		}
+		if rest[0] == "debug" {
+			playerID = ""
+		}
+		s.upgrade(w, r, playerID)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (s *Server) upgrade(w http.ResponseWriter, r *http.Request, playerID string) {
+	conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &client{conn: conn, playerID: playerID}
+	s.lock.Lock()
+	s.clients[c] = struct{}{}
+	s.lock.Unlock()
+
+	go func() {
+		defer func() {
+			s.lock.Lock()
+			delete(s.clients, c)
+			s.lock.Unlock()
+			conn.Close()
+		}()
+		for {
+			msgType, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msgType != websocket.TextMessage || playerID == "" {
+				continue
+			}
+			var cmd cg.Command
+			if json.Unmarshal(msg, &cmd) != nil {
+				continue
+			}
+			s.lock.Lock()
+			s.commands = append(s.commands, ReceivedCommand{PlayerID: playerID, Command: cmd})
+			close(s.changed)
+			s.changed = make(chan struct{})
+			s.lock.Unlock()
+		}
+	}()
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func randomSecret() string {
+	data := make([]byte, 16)
+	rand.Read(data)
+	return hex.EncodeToString(data)
+}
+
+// jsonEqual reports whether data and the JSON encoding of value represent the same JSON value.
+func jsonEqual(data json.RawMessage, value any) bool {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	var a, b any
+	if json.Unmarshal(data, &a) != nil || json.Unmarshal(encoded, &b) != nil {
+		return false
+	}
+	return reflect.DeepEqual(a, b)
+}
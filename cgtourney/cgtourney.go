@@ -0,0 +1,239 @@
+/*
+Package cgtourney runs tournaments between bots on a CodeGame server and computes the standings.
+*/
+package cgtourney
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+// Bot is a participant of a tournament.
+type Bot struct {
+	Name string
+	// Play implements the logic of the bot. It is called with a socket connected to a new player in each game
+	// and should return when the game is over or the socket is closed.
+	Play func(socket *cg.Socket) error
+}
+
+// Result contains the score of every player of a game by player ID.
+type Result struct {
+	Scores map[string]float64
+}
+
+// Judge watches a game as a spectator and determines the result.
+// players maps player IDs to the names of the bots controlling them.
+type Judge func(spectator *cg.Socket, players map[string]string) (Result, error)
+
+type Config struct {
+	GameURL string
+	Bots    []Bot
+	// PlayersPerGame is the number of bots in each game. Every combination of bots is played.
+	PlayersPerGame int
+	// Rounds is the number of times each combination is played. Values <= 0 are treated as 1.
+	Rounds int
+	// GameConfig is used when creating the games.
+	GameConfig any
+	Judge      Judge
+	// Parallel is the maximum number of games running at the same time. Values <= 0 are treated as 1.
+	Parallel int
+}
+
+// Standing is the tournament result of a single bot.
+type Standing struct {
+	Bot    string
+	Games  int
+	Wins   int
+	Points float64
+	Errors int
+}
+
+// Standings are sorted by points, then wins.
+type Standings []Standing
+
+func (s Standings) String() string {
+	var builder strings.Builder
+	w := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tBOT\tGAMES\tWINS\tPOINTS\tERRORS")
+	for i, standing := range s {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%g\t%d\n", i+1, standing.Bot, standing.Games, standing.Wins, standing.Points, standing.Errors)
+	}
+	w.Flush()
+	return builder.String()
+}
+
+// Run plays all matchups and returns the standings.
+// Games that fail are counted as errors for the participating bots and bots whose Play function
+// returns an error are counted as errors for that bot.
+func Run(config Config) (Standings, error) {
+	if config.PlayersPerGame <= 0 || config.PlayersPerGame > len(config.Bots) {
+		return nil, fmt.Errorf("invalid number of players per game: %d", config.PlayersPerGame)
+	}
+	if config.Judge == nil {
+		return nil, errors.New("missing judge")
+	}
+	if config.Rounds <= 0 {
+		config.Rounds = 1
+	}
+	if config.Parallel <= 0 {
+		config.Parallel = 1
+	}
+
+	standings := make(map[string]*Standing, len(config.Bots))
+	for _, bot := range config.Bots {
+		if _, ok := standings[bot.Name]; ok {
+			return nil, fmt.Errorf("duplicate bot name: %s", bot.Name)
+		}
+		standings[bot.Name] = &Standing{Bot: bot.Name}
+	}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, config.Parallel)
+	for round := 0; round < config.Rounds; round++ {
+		for _, matchup := range combinations(len(config.Bots), config.PlayersPerGame) {
+			bots := make([]Bot, len(matchup))
+			for i, index := range matchup {
+				bots[i] = config.Bots[index]
+			}
+
+			slots <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+				result, players, botErrs, err := playGame(config, bots)
+
+				lock.Lock()
+				defer lock.Unlock()
+				for i, bot := range bots {
+					standings[bot.Name].Games++
+					if err != nil || botErrs[i] != nil {
+						standings[bot.Name].Errors++
+					}
+				}
+				if err != nil {
+					return
+				}
+				best := 0.0
+				for i, playerID := range playerIDs(players) {
+					if score := result.Scores[playerID]; i == 0 || score > best {
+						best = score
+					}
+				}
+				for playerID, name := range players {
+					score := result.Scores[playerID]
+					standings[name].Points += score
+					if score == best {
+						standings[name].Wins++
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	result := make(Standings, 0, len(standings))
+	for _, standing := range standings {
+		result = append(result, *standing)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Points != result[j].Points {
+			return result[i].Points > result[j].Points
+		}
+		if result[i].Wins != result[j].Wins {
+			return result[i].Wins > result[j].Wins
+		}
+		return result[i].Bot < result[j].Bot
+	})
+	return result, nil
+}
+
+// playGame plays a single game and returns its result, the players and the errors returned by the Play functions of bots.
+func playGame(config Config, bots []Bot) (Result, map[string]string, []error, error) {
+	gameID, _, err := cg.CreateGame(config.GameURL, false, false, config.GameConfig)
+	if err != nil {
+		return Result{}, nil, nil, fmt.Errorf("failed to create game: %w", err)
+	}
+
+	spectator, err := cg.Dial(config.GameURL, cg.WithGame(gameID))
+	if err != nil {
+		return Result{}, nil, nil, fmt.Errorf("failed to spectate game: %w", err)
+	}
+	defer spectator.Close()
+
+	players := make(map[string]string, len(bots))
+	sockets := make([]*cg.Socket, 0, len(bots))
+	defer func() {
+		for _, socket := range sockets {
+			socket.Close()
+		}
+	}()
+	for _, bot := range bots {
+		playerID, playerSecret, err := cg.JoinGame(config.GameURL, gameID, bot.Name, "")
+		if err != nil {
+			return Result{}, nil, nil, fmt.Errorf("failed to join game with %s: %w", bot.Name, err)
+		}
+		socket, err := cg.Connect(config.GameURL, gameID, playerID, playerSecret)
+		if err != nil {
+			return Result{}, nil, nil, fmt.Errorf("failed to connect %s: %w", bot.Name, err)
+		}
+		players[playerID] = bot.Name
+		sockets = append(sockets, socket)
+	}
+
+	botErrs := make([]error, len(bots))
+	var wg sync.WaitGroup
+	for i, bot := range bots {
+		wg.Add(1)
+		go func(i int, bot Bot) {
+			defer wg.Done()
+			botErrs[i] = bot.Play(sockets[i])
+		}(i, bot)
+	}
+
+	result, err := config.Judge(spectator, players)
+	// Stop the bots that are still playing before their sockets are released.
+	for _, socket := range sockets {
+		socket.Close()
+	}
+	wg.Wait()
+	sockets = nil
+	return result, players, botErrs, err
+}
+
+func playerIDs(players map[string]string) []string {
+	ids := make([]string, 0, len(players))
+	for id := range players {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// combinations returns all k-element subsets of {0, ..., n-1} in lexicographic order.
+func combinations(n, k int) [][]int {
+	var result [][]int
+	current := make([]int, 0, k)
+	var generate func(start int)
+	generate = func(start int) {
+		if len(current) == k {
+			result = append(result, append([]int(nil), current...))
+			return
+		}
+		for i := start; i < n; i++ {
+			current = append(current, i)
+			generate(i + 1)
+			current = current[:len(current)-1]
+		}
+	}
+	generate(0)
+	return result
+}
@@ -0,0 +1,60 @@
+package cgtourney_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/code-game-project/go-client/cg"
+	"github.com/code-game-project/go-client/cgtest"
+	"github.com/code-game-project/go-client/cgtourney"
+)
+
+func TestRunCountsBotErrors(t *testing.T) {
+	server := cgtest.NewServer(t)
+	var running int32
+	play := func(socket *cg.Socket) error {
+		atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		return socket.RunEventLoop()
+	}
+	standings, err := cgtourney.Run(cgtourney.Config{
+		GameURL: server.URL,
+		Bots: []cgtourney.Bot{
+			{Name: "good", Play: play},
+			{Name: "broken", Play: func(*cg.Socket) error {
+				return errors.New("crashed")
+			}},
+		},
+		PlayersPerGame: 2,
+		Rounds:         2,
+		Judge: func(spectator *cg.Socket, players map[string]string) (cgtourney.Result, error) {
+			scores := make(map[string]float64, len(players))
+			for playerID, name := range players {
+				if name == "good" {
+					scores[playerID] = 1
+				}
+			}
+			return cgtourney.Result{Scores: scores}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %s", err)
+	}
+	if n := atomic.LoadInt32(&running); n != 0 {
+		t.Errorf("expected all bots to have returned, %d still running", n)
+	}
+
+	expected := cgtourney.Standings{
+		{Bot: "good", Games: 2, Wins: 2, Points: 2},
+		{Bot: "broken", Games: 2, Errors: 2},
+	}
+	if len(standings) != len(expected) {
+		t.Fatalf("expected %d standings, got %d", len(expected), len(standings))
+	}
+	for i := range expected {
+		if standings[i] != expected[i] {
+			t.Errorf("expected standing %+v, got %+v", expected[i], standings[i])
+		}
+	}
+}
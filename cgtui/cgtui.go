@@ -0,0 +1,334 @@
+/*
+Package cgtui implements a terminal spectator UI that shows the live events, the players and the debug messages of a game in separate panes.
+It is used by the spectate command of the CodeGame CLI and can be embedded by game-specific clients.
+*/
+package cgtui
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/code-game-project/go-client/cg"
+)
+
+// maxLines limits the number of lines kept per pane.
+const maxLines = 1000
+
+type line struct {
+	text  string
+	style tcell.Style
+}
+
+// Viewer renders the events received by a socket. It only registers listeners,
+// so the event loop of the socket has to be run separately, e.g. with RunEventLoop in another goroutine.
+type Viewer struct {
+	// Title is shown in the status line. Defaults to the game ID.
+	Title string
+
+	socket *cg.Socket
+
+	lock     sync.Mutex
+	events   []line
+	debug    []line
+	players  map[string]string
+	hasDebug bool
+	// scroll is the number of event lines scrolled back from the newest one.
+	scroll int
+	screen tcell.Screen
+}
+
+// New creates a viewer for the events and players of socket.
+// The player roster is read from socket.RosterChanges, which must not be consumed by anyone else.
+func New(socket *cg.Socket) *Viewer {
+	v := &Viewer{
+		Title:   socket.GameID(),
+		socket:  socket,
+		players: make(map[string]string),
+	}
+	socket.OnAny(func(event cg.Event) {
+		v.addEvent(event)
+	})
+	go v.watchRoster(socket.RosterChanges(), socket.Done())
+	return v
+}
+
+// AttachDebug shows the messages of debug in a separate pane.
+func (v *Viewer) AttachDebug(debug *cg.DebugSocket) {
+	v.lock.Lock()
+	v.hasDebug = true
+	v.lock.Unlock()
+	debug.OnMessage(func(severity cg.DebugSeverity, message, data string) {
+		v.addDebug(severity, message, data)
+	})
+}
+
+// Run takes over the terminal until the user quits with q, Esc or Ctrl+C or the connection of the socket ends.
+// It returns nil if the user quit and the error of the socket otherwise.
+func (v *Viewer) Run() error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	err = screen.Init()
+	if err != nil {
+		return err
+	}
+	defer screen.Fini()
+	return v.RunScreen(screen)
+}
+
+// RunScreen is like Run but draws on an initialized screen, which is not finalized afterwards.
+func (v *Viewer) RunScreen(screen tcell.Screen) error {
+	v.lock.Lock()
+	v.screen = screen
+	v.lock.Unlock()
+	defer func() {
+		v.lock.Lock()
+		v.screen = nil
+		v.lock.Unlock()
+	}()
+
+	done := v.socket.Done()
+	go func() {
+		<-done
+		screen.PostEvent(tcell.NewEventInterrupt(done))
+	}()
+
+	v.draw()
+	for {
+		switch ev := screen.PollEvent().(type) {
+		case nil:
+			return nil
+		case *tcell.EventResize:
+			screen.Sync()
+		case *tcell.EventKey:
+			if v.handleKey(ev) {
+				return nil
+			}
+		case *tcell.EventInterrupt:
+			if ev.Data() == done {
+				err := v.socket.Err()
+				if err == cg.ErrClosed {
+					return nil
+				}
+				return err
+			}
+		}
+		v.draw()
+	}
+}
+
+// handleKey reports whether the user wants to quit.
+func (v *Viewer) handleKey(ev *tcell.EventKey) bool {
+	_, height := v.screen.Size()
+	page := height / 2
+	if page < 1 {
+		page = 1
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	switch ev.Key() {
+	case tcell.KeyEscape, tcell.KeyCtrlC:
+		return true
+	case tcell.KeyRune:
+		if ev.Rune() == 'q' {
+			return true
+		}
+	case tcell.KeyUp:
+		v.scroll++
+	case tcell.KeyDown:
+		v.scroll--
+	case tcell.KeyPgUp:
+		v.scroll += page
+	case tcell.KeyPgDn:
+		v.scroll -= page
+	case tcell.KeyHome:
+		v.scroll = len(v.events)
+	case tcell.KeyEnd:
+		v.scroll = 0
+	}
+	if v.scroll > len(v.events)-1 {
+		v.scroll = len(v.events) - 1
+	}
+	if v.scroll < 0 {
+		v.scroll = 0
+	}
+	return false
+}
+
+func (v *Viewer) addEvent(event cg.Event) {
+	header := tcell.StyleDefault.Foreground(tcell.ColorTeal).Bold(true)
+	lines := strings.Split(cg.FormatEvent(event, false), "\n")
+	v.lock.Lock()
+	for i, text := range lines {
+		style := tcell.StyleDefault
+		if i == 0 {
+			style = header
+		}
+		v.events = append(v.events, line{text: text, style: style})
+	}
+	if v.scroll > 0 {
+		// Keep the scrolled back view in place.
+		v.scroll += len(lines)
+	}
+	v.events = trimLines(v.events)
+	v.lock.Unlock()
+	v.refresh()
+}
+
+func (v *Viewer) addDebug(severity cg.DebugSeverity, message, data string) {
+	style := tcell.StyleDefault
+	switch severity {
+	case cg.DebugError:
+		style = style.Foreground(tcell.ColorRed)
+	case cg.DebugWarning:
+		style = style.Foreground(tcell.ColorYellow)
+	case cg.DebugTrace:
+		style = style.Dim(true)
+	}
+	text := "[" + string(severity) + "] " + message
+	if data != "" {
+		text += " " + data
+	}
+	v.lock.Lock()
+	for _, l := range strings.Split(text, "\n") {
+		v.debug = append(v.debug, line{text: l, style: style})
+	}
+	v.debug = trimLines(v.debug)
+	v.lock.Unlock()
+	v.refresh()
+}
+
+func (v *Viewer) watchRoster(changes <-chan cg.RosterEvent, done <-chan struct{}) {
+	for {
+		select {
+		case change := <-changes:
+			v.lock.Lock()
+			if change.Kind == cg.RosterLeave {
+				delete(v.players, change.PlayerID)
+			} else {
+				v.players[change.PlayerID] = change.Username
+			}
+			v.lock.Unlock()
+			v.refresh()
+		case <-done:
+			return
+		}
+	}
+}
+
+// refresh wakes up the UI loop to redraw the screen.
+func (v *Viewer) refresh() {
+	v.lock.Lock()
+	screen := v.screen
+	v.lock.Unlock()
+	if screen != nil {
+		screen.PostEvent(tcell.NewEventInterrupt(nil))
+	}
+}
+
+func trimLines(lines []line) []line {
+	if len(lines) <= maxLines {
+		return lines
+	}
+	return append(lines[:0], lines[len(lines)-maxLines:]...)
+}
+
+func (v *Viewer) draw() {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	screen := v.screen
+	screen.Clear()
+	width, height := screen.Size()
+
+	mainHeight := height - 1
+	if v.hasDebug {
+		mainHeight = height * 2 / 3
+	}
+	playersWidth := width / 4
+	if playersWidth > 30 {
+		playersWidth = 30
+	}
+
+	drawPane(screen, 0, 0, width-playersWidth, mainHeight, "Events", v.events, v.scroll)
+	drawPane(screen, width-playersWidth, 0, playersWidth, mainHeight, "Players", v.playerLines(), 0)
+	if v.hasDebug {
+		drawPane(screen, 0, mainHeight, width, height-1-mainHeight, "Debug", v.debug, 0)
+	}
+
+	status := v.Title + "  q: quit  ↑/↓ PgUp/PgDn: scroll  End: follow"
+	if v.scroll > 0 {
+		status += "  (scrolled back)"
+	}
+	drawText(screen, 0, height-1, width, status, tcell.StyleDefault.Reverse(true))
+	screen.Show()
+}
+
+func (v *Viewer) playerLines() []line {
+	lines := make([]line, 0, len(v.players))
+	for id, username := range v.players {
+		lines = append(lines, line{text: username + " (" + id + ")", style: tcell.StyleDefault})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].text < lines[j].text
+	})
+	return lines
+}
+
+// drawPane draws a box with the last lines that fit, skipping the newest scroll lines.
+func drawPane(screen tcell.Screen, x, y, width, height int, title string, lines []line, scroll int) {
+	if width < 2 || height < 2 {
+		return
+	}
+	border := tcell.StyleDefault.Dim(true)
+	for col := x + 1; col < x+width-1; col++ {
+		screen.SetContent(col, y, tcell.RuneHLine, nil, border)
+		screen.SetContent(col, y+height-1, tcell.RuneHLine, nil, border)
+	}
+	for row := y + 1; row < y+height-1; row++ {
+		screen.SetContent(x, row, tcell.RuneVLine, nil, border)
+		screen.SetContent(x+width-1, row, tcell.RuneVLine, nil, border)
+	}
+	screen.SetContent(x, y, tcell.RuneULCorner, nil, border)
+	screen.SetContent(x+width-1, y, tcell.RuneURCorner, nil, border)
+	screen.SetContent(x, y+height-1, tcell.RuneLLCorner, nil, border)
+	screen.SetContent(x+width-1, y+height-1, tcell.RuneLRCorner, nil, border)
+	drawText(screen, x+2, y, width-4, " "+title+" ", tcell.StyleDefault.Bold(true))
+
+	rows := height - 2
+	end := len(lines) - scroll
+	if end < 0 {
+		end = 0
+	}
+	start := end - rows
+	if start < 0 {
+		start = 0
+	}
+	for i, l := range lines[start:end] {
+		drawText(screen, x+1, y+1+i, width-2, l.text, l.style)
+	}
+}
+
+// drawText draws text in a single row, cutting it off at width cells.
+func drawText(screen tcell.Screen, x, y, width int, text string, style tcell.Style) {
+	col := x
+	for _, r := range text {
+		if r == '\t' {
+			r = ' '
+		}
+		w := runewidth.RuneWidth(r)
+		if w == 0 {
+			continue
+		}
+		if col+w > x+width {
+			return
+		}
+		screen.SetContent(col, y, r, nil, style)
+		col += w
+	}
+}
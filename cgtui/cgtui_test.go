@@ -0,0 +1,121 @@
+package cgtui_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/code-game-project/go-client/cgtest"
+	"github.com/code-game-project/go-client/cgtui"
+)
+
+// testScreen records the text of the simulation screen whenever it is shown.
+type testScreen struct {
+	tcell.SimulationScreen
+	lock sync.Mutex
+	text string
+}
+
+func newTestScreen(t *testing.T) *testScreen {
+	s := &testScreen{SimulationScreen: tcell.NewSimulationScreen("UTF-8")}
+	err := s.Init()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Fini)
+	s.SetSize(100, 30)
+	return s
+}
+
+// Show is called by the UI loop, so the cells can be read without racing with the next draw.
+func (s *testScreen) Show() {
+	s.SimulationScreen.Show()
+	cells, width, _ := s.GetContents()
+	var b strings.Builder
+	for i, cell := range cells {
+		if i > 0 && i%width == 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(string(cell.Runes))
+	}
+	s.lock.Lock()
+	s.text = b.String()
+	s.lock.Unlock()
+}
+
+func (s *testScreen) waitFor(t *testing.T, text string, shown bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		s.lock.Lock()
+		current := s.text
+		s.lock.Unlock()
+		if strings.Contains(current, text) == shown {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q to be shown: %t, screen:\n%s", text, shown, current)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func expectReturn(t *testing.T, runErr <-chan error) {
+	t.Helper()
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Fatalf("expected RunScreen to return nil, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected RunScreen to return")
+	}
+}
+
+func TestViewer(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := server.Connect(t, "alice")
+	viewer := cgtui.New(socket)
+	go socket.RunEventLoop()
+
+	screen := newTestScreen(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- viewer.RunScreen(screen)
+	}()
+
+	screen.waitFor(t, "alice (player_1)", true)
+	server.Emit("greeting", map[string]string{"text": "hello"})
+	server.Emit("farewell", nil)
+	screen.waitFor(t, "greeting", true)
+	screen.waitFor(t, "farewell", true)
+	screen.waitFor(t, server.GameID+"  q: quit", true)
+
+	screen.InjectKey(tcell.KeyUp, 0, tcell.ModNone)
+	screen.waitFor(t, "(scrolled back)", true)
+	screen.InjectKey(tcell.KeyEnd, 0, tcell.ModNone)
+	screen.waitFor(t, "(scrolled back)", false)
+
+	screen.InjectKey(tcell.KeyRune, 'q', tcell.ModNone)
+	expectReturn(t, runErr)
+}
+
+func TestViewerReturnsWhenSocketCloses(t *testing.T) {
+	server := cgtest.NewServer(t)
+	socket := server.Connect(t, "alice")
+	viewer := cgtui.New(socket)
+	go socket.RunEventLoop()
+
+	screen := newTestScreen(t)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- viewer.RunScreen(screen)
+	}()
+	screen.waitFor(t, "Events", true)
+
+	socket.Close()
+	expectReturn(t, runErr)
+}
@@ -4,4 +4,18 @@ go 1.18
 
 retract v0.9.1 // contains CG v0.8 code
 
-require github.com/gorilla/websocket v1.5.0
+require (
+	github.com/coder/websocket v1.8.12
+	github.com/gdamore/tcell/v2 v2.7.4
+	github.com/gorilla/websocket v1.5.0
+	github.com/mattn/go-runewidth v0.0.15
+)
+
+require (
+	github.com/gdamore/encoding v1.0.0 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/rivo/uniseg v0.4.3 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	golang.org/x/term v0.17.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+)
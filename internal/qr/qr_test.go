@@ -0,0 +1,180 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// The byte capacities of versions 1 to 10 at error correction level M are 14, 26, 42, 62, 84, 106, 122, 152, 180 and 213.
+func TestEncodeVersion(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{0, 1}, {14, 1}, {15, 2}, {26, 2}, {27, 3}, {42, 3}, {43, 4}, {62, 4}, {63, 5},
+		{84, 5}, {85, 6}, {106, 6}, {107, 7}, {122, 7}, {123, 8}, {152, 8}, {153, 9}, {180, 9}, {181, 10}, {213, 10},
+	}
+	for _, test := range tests {
+		code, err := Encode(bytes.Repeat([]byte("a"), test.length))
+		if err != nil {
+			t.Errorf("%d bytes: %s", test.length, err)
+			continue
+		}
+		if expected := 17 + 4*test.version; code.Size != expected {
+			t.Errorf("%d bytes: expected version %d with size %d, got size %d", test.length, test.version, expected, code.Size)
+		}
+	}
+
+	_, err := Encode(bytes.Repeat([]byte("a"), 214))
+	if err != ErrTooLong {
+		t.Errorf("expected %v, got %v", ErrTooLong, err)
+	}
+}
+
+// formatBits reads both copies of the format information of c.
+func formatBits(c *Code) (int, int) {
+	var first, second int
+	bit := func(bits *int, i, x, y int) {
+		if c.Modules[y][x] {
+			*bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		bit(&first, i, 8, i)
+	}
+	bit(&first, 6, 8, 7)
+	bit(&first, 7, 8, 8)
+	bit(&first, 8, 7, 8)
+	for i := 9; i < 15; i++ {
+		bit(&first, i, 14-i, 8)
+	}
+	for i := 0; i < 8; i++ {
+		bit(&second, i, c.Size-1-i, 8)
+	}
+	for i := 8; i < 15; i++ {
+		bit(&second, i, 8, c.Size-15+i)
+	}
+	return first, second
+}
+
+func TestFormatInformation(t *testing.T) {
+	// The format information of error correction level M for the masks 0 to 7.
+	expected := [8]int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+	for mask, bits := range expected {
+		c := encode(nil, 1, 8)
+		c.drawFormat(mask)
+		first, second := formatBits(c)
+		if first != bits || second != bits {
+			t.Errorf("mask %d: expected %015b, got %015b and %015b", mask, bits, first, second)
+		}
+	}
+
+	for _, data := range []string{"CodeGame", strings.Repeat("a", 100)} {
+		code, err := Encode([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		first, second := formatBits(code)
+		if first != second {
+			t.Errorf("%d bytes: the copies of the format information differ: %015b and %015b", len(data), first, second)
+		}
+		// The error correction level is stored in the two most significant bits, which are 00 for level M.
+		if level := (first ^ 0x5412) >> 13; level != 0 {
+			t.Errorf("%d bytes: expected error correction level M, got %02b", len(data), level)
+		}
+	}
+}
+
+func TestVersionInformation(t *testing.T) {
+	tests := []struct {
+		version int
+		bits    int
+	}{
+		{7, 0x07C94},
+		{8, 0x085BC},
+		{9, 0x09A99},
+		{10, 0x0A4D3},
+	}
+	for _, test := range tests {
+		c := encode(nil, test.version, 8)
+		var topRight, bottomLeft int
+		for i := 0; i < 18; i++ {
+			a, b := c.Size-11+i%3, i/3
+			if c.Modules[b][a] {
+				topRight |= 1 << i
+			}
+			if c.Modules[a][b] {
+				bottomLeft |= 1 << i
+			}
+		}
+		if topRight != test.bits || bottomLeft != test.bits {
+			t.Errorf("version %d: expected %018b, got %018b and %018b", test.version, test.bits, topRight, bottomLeft)
+		}
+	}
+}
+
+func TestDataCodewords(t *testing.T) {
+	expected := []byte{0x40, 0x56, 0x86, 0x56, 0xC6, 0xC6, 0xF0, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC}
+	actual := dataCodewords([]byte("hello"), 8, 16)
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("expected % X, got % X", expected, actual)
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	// The codewords of HELLO WORLD at version 1 with level M.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	actual := reedSolomon(data, 10)
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestEncodeModules(t *testing.T) {
+	// Version 1, level M, mask 6.
+	expected := []string{
+		"#######.#.#...#######",
+		"#.....#.#####.#.....#",
+		"#.###.#.##.##.#.###.#",
+		"#.###.#..#..#.#.###.#",
+		"#.###.#.###.#.#.###.#",
+		"#.....#..####.#.....#",
+		"#######.#.#.#.#######",
+		".........#.##........",
+		"#..######...##..#.###",
+		"#.##...#.#..###..#...",
+		"###...##.....##....##",
+		"#.#.##.###.#...##.###",
+		"..###.##.....##..#.##",
+		"........#####...#####",
+		"#######.#.#.###.#.#..",
+		"#.....#.##.###.####.#",
+		"#.###.#.##.##.##.#...",
+		"#.###.#.#.###.....#..",
+		"#.###.#...#...#.#.###",
+		"#.....#......########",
+		"#######.#.##....#.#..",
+	}
+	code, err := Encode([]byte("CodeGame"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Size != len(expected) {
+		t.Fatalf("expected size %d, got %d", len(expected), code.Size)
+	}
+	for y, row := range code.Modules {
+		var line strings.Builder
+		for _, dark := range row {
+			if dark {
+				line.WriteByte('#')
+			} else {
+				line.WriteByte('.')
+			}
+		}
+		if line.String() != expected[y] {
+			t.Errorf("row %d: expected %s, got %s", y, expected[y], line.String())
+		}
+	}
+}